@@ -0,0 +1,129 @@
+// Assignment (ASR) and increment/decrement (IDD) mutators, they change how a variable is updated
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+type ASRAddToSub struct{}
+
+func (ASRAddToSub) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRAddToSub", source, path, info, orig, token.ADD_ASSIGN, token.SUB_ASSIGN)
+}
+
+type ASRSubToAdd struct{}
+
+func (ASRSubToAdd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRSubToAdd", source, path, info, orig, token.SUB_ASSIGN, token.ADD_ASSIGN)
+}
+
+type ASRMulToQuo struct{}
+
+func (ASRMulToQuo) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRMulToQuo", source, path, info, orig, token.MUL_ASSIGN, token.QUO_ASSIGN)
+}
+
+type ASRQuoToMul struct{}
+
+func (ASRQuoToMul) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRQuoToMul", source, path, info, orig, token.QUO_ASSIGN, token.MUL_ASSIGN)
+}
+
+type ASRRemToMul struct{}
+
+func (ASRRemToMul) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRRemToMul", source, path, info, orig, token.REM_ASSIGN, token.MUL_ASSIGN)
+}
+
+type ASRAndToOr struct{}
+
+func (ASRAndToOr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRAndToOr", source, path, info, orig, token.AND_ASSIGN, token.OR_ASSIGN)
+}
+
+type ASROrToAnd struct{}
+
+func (ASROrToAnd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASROrToAnd", source, path, info, orig, token.OR_ASSIGN, token.AND_ASSIGN)
+}
+
+type ASRXorToOr struct{}
+
+func (ASRXorToOr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRXorToOr", source, path, info, orig, token.XOR_ASSIGN, token.OR_ASSIGN)
+}
+
+type ASRAndNotToAnd struct{}
+
+func (ASRAndNotToAnd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRAndNotToAnd", source, path, info, orig, token.AND_NOT_ASSIGN, token.AND_ASSIGN)
+}
+
+type ASRShlToShr struct{}
+
+func (ASRShlToShr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRShlToShr", source, path, info, orig, token.SHL_ASSIGN, token.SHR_ASSIGN)
+}
+
+type ASRShrToShl struct{}
+
+func (ASRShrToShl) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return assignReplacement("ASRShrToShl", source, path, info, orig, token.SHR_ASSIGN, token.SHL_ASSIGN)
+}
+
+type ASRToAssign struct{}
+
+// x += y => x = y. Shifts are left out, their right side may have another type
+func (ASRToAssign) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.AssignStmt)
+	if !ok || stmt.Tok == token.SHL_ASSIGN || stmt.Tok == token.SHR_ASSIGN || assignOperator(stmt.Tok) == token.ILLEGAL {
+		return nil
+	}
+	return assignReplacement("ASRToAssign", source, path, info, orig, stmt.Tok, token.ASSIGN)
+}
+
+func assignReplacement(issuer string, source string, path []ast.Node, info *types.Info, orig ast.Node, tok token.Token, newTok token.Token) *Replacement {
+	stmt, ok := orig.(*ast.AssignStmt)
+	if !ok || stmt.Tok != tok || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+		return nil
+	}
+	if op := assignOperator(newTok); op != token.ILLEGAL && !validOperator(info, &ast.BinaryExpr{X: stmt.Lhs[0], Op: op, Y: stmt.Rhs[0]}, op) {
+		return nil
+	}
+
+	newStr := source[stmt.Pos():stmt.TokPos] + newTok.String() + source[int(stmt.TokPos)+len(tok.String()):stmt.End()]
+	return &Replacement{issuer, stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+// Binary operator of an assignment operator, += is +. ILLEGAL for other tokens
+func assignOperator(tok token.Token) token.Token {
+	// Both kinds of operators are declared in the same order
+	if tok < token.ADD_ASSIGN || tok > token.AND_NOT_ASSIGN {
+		return token.ILLEGAL
+	}
+	return tok - token.ADD_ASSIGN + token.ADD
+}
+
+type IDDIncToDec struct{}
+
+func (IDDIncToDec) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return incDecReplacement("IDDIncToDec", source, orig, token.INC, token.DEC)
+}
+
+type IDDDecToInc struct{}
+
+func (IDDDecToInc) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return incDecReplacement("IDDDecToInc", source, orig, token.DEC, token.INC)
+}
+
+func incDecReplacement(issuer string, source string, orig ast.Node, tok token.Token, newTok token.Token) *Replacement {
+	stmt, ok := orig.(*ast.IncDecStmt)
+	if !ok || stmt.Tok != tok {
+		return nil
+	}
+
+	newStr := source[stmt.Pos():stmt.TokPos] + newTok.String()
+	return &Replacement{issuer, stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
@@ -0,0 +1,156 @@
+// Function call (CALL) mutators, they remove calls or change what they receive and give back
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+type CALLRemoveVoid struct{}
+
+// Calls without results are removed. Calls owned by the concurrency mutators are left to them
+func (CALLRemoveVoid) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.ExprStmt)
+	if !ok || !isVoidCall(stmt, info) || isTerminatingPanic(stmt, path) {
+		return nil
+	}
+
+	call := stmt.X.(*ast.CallExpr)
+	if _, ok := LOCK_METHODS[methodName(call, info)]; ok || isConcurrencyCall(call, info) {
+		return nil
+	}
+	return statementDeletion("CALLRemoveVoid", source, path, stmt)
+}
+
+func isVoidCall(stmt *ast.ExprStmt, info *types.Info) bool {
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok || info == nil {
+		return false
+	}
+	tv, ok := info.Types[call]
+	return ok && tv.IsVoid()
+}
+
+type CALLSwapArgs struct{}
+
+// Swaps the first two neighbouring arguments that have the same type and are not the same expression
+func (CALLSwapArgs) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	call, ok := orig.(*ast.CallExpr)
+	if !ok || info == nil || len(call.Args) < 2 || call.Ellipsis.IsValid() {
+		return nil
+	}
+
+	for i := 0; i+1 < len(call.Args); i++ {
+		a, b := call.Args[i], call.Args[i+1]
+		typeA, typeB := info.TypeOf(a), info.TypeOf(b)
+		if typeA == nil || typeB == nil || !types.Identical(typeA, typeB) || info.Types[a].IsType() {
+			continue
+		}
+		if source[a.Pos():a.End()] == source[b.Pos():b.End()] {
+			continue
+		}
+		// Constant sizes given to make are checked when compiling, a length above the capacity wouldn't build
+		if isBuiltin(call.Fun, "make", info) && info.Types[a].Value != nil && info.Types[b].Value != nil {
+			continue
+		}
+
+		stmt := RootStmt(path)
+		if stmt == nil {
+			return nil
+		}
+		args := append([]ast.Expr{}, call.Args...)
+		args[i], args[i+1] = b, a
+		new := &ast.CallExpr{Fun: call.Fun, Args: args}
+		oldStr, newStr := MutationString(source, stmt, call, new)
+		return &Replacement{"CALLSwapArgs", call, stmt, newStr, oldStr}
+	}
+	return nil
+}
+
+type CALLZeroResult struct{}
+
+// The result of the call is replaced by the zero value of its type: f(x) => 0
+func (CALLZeroResult) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	call, ok := orig.(*ast.CallExpr)
+	if !ok || !isRead(call, path, info) || info.Types[call.Fun].IsType() {
+		return nil
+	}
+	switch path[len(path)-2].(type) {
+	case *ast.ExprStmt, *ast.GoStmt, *ast.DeferStmt:
+		return nil
+	}
+	// Returned values are already replaced by the RET mutators
+	if ret, _ := returnedValue(call, path, info); ret != nil || untypedContext(path, info) {
+		return nil
+	}
+	// Bounds like len(s) - 1 would become constant and negative: s[len(s)-1] => s[0-1]
+	if isInBound(path) {
+		return nil
+	}
+	return exprReplacement("CALLZeroResult", source, path, call, zeroValue(info.TypeOf(call), path[0].(*ast.File), info))
+}
+
+// Whether the expression is part of an index or a slice bound
+func isInBound(path []ast.Node) bool {
+	for i := len(path) - 1; i > 0; i-- {
+		switch parent := path[i-1].(type) {
+		case *ast.IndexExpr:
+			if parent.Index == path[i] {
+				return true
+			}
+		case *ast.SliceExpr:
+			if parent.Low == path[i] || parent.High == path[i] || parent.Max == path[i] {
+				return true
+			}
+		case ast.Expr:
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// Whether nothing around the expression would give a type to an untyped zero value that replaces it
+func untypedContext(path []ast.Node, info *types.Info) bool {
+	switch parent := path[len(path)-2].(type) {
+	case *ast.AssignStmt:
+		return parent.Tok == token.DEFINE || len(parent.Lhs) != len(parent.Rhs)
+	case *ast.ValueSpec:
+		return parent.Type == nil
+	case *ast.CallExpr:
+		ident, ok := parent.Fun.(*ast.Ident)
+		return ok && isBuiltin(ident, ident.Name, info)
+	case *ast.BinaryExpr:
+		return info.Types[parent.X].IsNil() || info.Types[parent.Y].IsNil()
+	case *ast.UnaryExpr:
+		return parent.Op == token.ARROW
+	case *ast.IndexExpr:
+		return parent.X == path[len(path)-1]
+	case *ast.SliceExpr:
+		return parent.X == path[len(path)-1]
+	case *ast.SwitchStmt:
+		// Untyped tags take their default type, which may not match the cases
+		return parent.Tag == path[len(path)-1]
+	case *ast.StarExpr, *ast.TypeAssertExpr, *ast.RangeStmt:
+		return true
+	}
+	return false
+}
+
+type CALLAppendToSlice struct{}
+
+// append(s, x) => s
+func (CALLAppendToSlice) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	call, ok := orig.(*ast.CallExpr)
+	if !ok || len(call.Args) < 2 || !isBuiltin(call.Fun, "append", info) {
+		return nil
+	}
+
+	stmt := RootStmt(path)
+	if stmt == nil {
+		return nil
+	}
+	oldStr, newStr := MutationString(source, stmt, call, call.Args[0])
+	return &Replacement{"CALLAppendToSlice", call, stmt, newStr, oldStr}
+}
@@ -0,0 +1,80 @@
+// Switch (SW) and select (SEL) mutators, they remove the code of case clauses
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+type SWDeleteCaseBody struct{}
+
+// Clauses of a switch that makes the function terminate are kept, without them it would be missing a return
+func (SWDeleteCaseBody) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	clause, ok := orig.(*ast.CaseClause)
+	if !ok || clause.List == nil || isTerminatingStmt(path, len(path)-1) {
+		return nil
+	}
+	return clauseBodyDeletion("SWDeleteCaseBody", source, clause, clause.Colon, clause.Body)
+}
+
+type SWEmptyDefault struct{}
+
+// Like SWDeleteCaseBody, defaults of a switch or select that makes the function terminate are kept
+func (SWEmptyDefault) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	if isTerminatingStmt(path, len(path)-1) {
+		return nil
+	}
+	switch clause := orig.(type) {
+	case *ast.CaseClause:
+		if clause.List == nil {
+			return clauseBodyDeletion("SWEmptyDefault", source, clause, clause.Colon, clause.Body)
+		}
+	case *ast.CommClause:
+		if clause.Comm == nil {
+			return clauseBodyDeletion("SWEmptyDefault", source, clause, clause.Colon, clause.Body)
+		}
+	}
+	return nil
+}
+
+// Keeps the case of the clause, its body is replaced by _ = with the variables it used
+func clauseBodyDeletion(issuer string, source string, clause ast.Stmt, colon token.Pos, body []ast.Stmt) *Replacement {
+	if len(body) == 0 {
+		return nil
+	}
+
+	newStr := source[clause.Pos() : colon+1]
+	if vars := usedVariables(clause); len(vars) > 0 {
+		newStr += " " + strings.Repeat("_, ", len(vars)-1) + "_ = " + strings.Join(vars, ", ")
+	}
+	return &Replacement{issuer, clause, clause, newStr, source[clause.Pos():clause.End()]}
+}
+
+type SWRemoveFallthrough struct{}
+
+func (SWRemoveFallthrough) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.BranchStmt)
+	if !ok || stmt.Tok != token.FALLTHROUGH {
+		return nil
+	}
+	return &Replacement{"SWRemoveFallthrough", stmt, stmt, "", source[stmt.Pos():stmt.End()]}
+}
+
+type SELDropCase struct{}
+
+// The whole communication clause is removed, so the select never takes it.
+// Variables it used are kept in a case on a channel that is never ready
+func (SELDropCase) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	clause, ok := orig.(*ast.CommClause)
+	if !ok || clause.Comm == nil {
+		return nil
+	}
+
+	newStr := ""
+	if vars := usedVariables(clause); len(vars) > 0 {
+		newStr = "case <-make(chan struct{}): " + strings.Repeat("_, ", len(vars)-1) + "_ = " + strings.Join(vars, ", ")
+	}
+	return &Replacement{"SELDropCase", clause, clause, newStr, source[clause.Pos():clause.End()]}
+}
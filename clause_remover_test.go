@@ -0,0 +1,85 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Replacements the mutator finds in the fixture, parsed like NewFileInfo does so positions are offsets
+func fixtureReplacements(t *testing.T, path string, mutator Mutator) []*Replacement {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fs token.FileSet
+	file, err := parser.ParseFile(&fs, path, source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replacements := []*Replacement{}
+	stack := []ast.Node{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, node)
+		if change := mutator.replacement(string(source), node, stack, nil); change != nil {
+			replacements = append(replacements, change)
+		}
+		return true
+	})
+	return replacements
+}
+
+func TestClauseMutators(t *testing.T) {
+	tests := []struct {
+		mutator Mutator
+		old     []string
+		new     []string
+	}{
+		{
+			SWDeleteCaseBody{},
+			[]string{"case int:\n\t\tfmt.Println(0)", "case 0:\n\t\tfmt.Println(0)\n\t\tfallthrough"},
+			[]string{"case int:", "case 0:"},
+		},
+		{
+			SWEmptyDefault{},
+			[]string{"default:\n\t\tfmt.Println(0)", "default:\n\t\tif true {\n\t\t\tfmt.Println(0)\n\t\t}"},
+			[]string{"default:", "default:"},
+		},
+		{
+			SWRemoveFallthrough{},
+			[]string{"fallthrough"},
+			[]string{""},
+		},
+		{
+			SELDropCase{},
+			[]string{
+				"case <-a:\n\t\tfmt.Println(0)", "case <-b:", "case <-a:", "case <-b:",
+				"case v := <-a:\n\t\ttotal += v\n\t\tfmt.Println(total)", "case <-time.After(time.Second):",
+			},
+			[]string{
+				"case <-make(chan struct{}): _ = a", "case <-make(chan struct{}): _ = b",
+				"case <-make(chan struct{}): _ = a", "case <-make(chan struct{}): _ = b",
+				"case <-make(chan struct{}): _, _ = a, total", "",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		old, new := []string{}, []string{}
+		for _, change := range fixtureReplacements(t, "kekw/testFile.go", test.mutator) {
+			old = append(old, change.OldStr)
+			new = append(new, change.NewStr)
+		}
+		if !reflect.DeepEqual(old, test.old) || !reflect.DeepEqual(new, test.new) {
+			t.Errorf("%T: got %q => %q, want %q => %q", test.mutator, old, new, test.old, test.new)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go/token"
 	"os"
 	"os/exec"
+	"strings"
 )
 
 const (
@@ -27,25 +28,53 @@ func __reach(msg string, flush bool) {
 	__LOGFILE.WriteString(msg+"\n")
 }
 `
+	// Written as an internal test file, so the external _test package can reach __reach through an import
+	REACH_EXPORT_FILE       = "golang_mut_export_test.go"
+	REACH_EXPORT_DEFINITION = `package %s
+
+func GolangMutReach(msg string, flush bool) {
+	__reach(msg, flush)
+}
+`
+	REACH_EXPORT_IMPORT = "__golang_mut"
 )
 
 type FileTable struct {
 	Files []*FileInfo
+	// Packages of the files, indexed by import path
+	Packages map[string]*PackageInfo
+	// Files created by the instrumentation
+	Generated []string
+}
+
+// Undoes the instrumentation
+func (ft *FileTable) Reset() {
+	for _, file := range ft.Files {
+		file.Reset()
+	}
+	for _, path := range ft.Generated {
+		os.Remove(path)
+	}
+	ft.Generated = nil
 }
 
 func (ft *FileTable) InstrumentPackage(pkg *PackageInfo) {
 	// If the package has no tests, or is empty: skip
-	if len(pkg.TestGoFiles) == 0 {
+	if len(pkg.TestGoFiles) == 0 && len(pkg.XTestGoFiles) == 0 {
 		fmt.Printf("?\t%s\t[no test files]\n", pkg.ImportPath)
 		return
-	} else if len(pkg.GoFiles) == 0 {
+	} else if len(pkg.SourceFiles()) == 0 {
 		fmt.Printf("?\t%s\t[no .go files]\n", pkg.ImportPath)
 		return
 	}
 
 	// For each Source file
-	for _, source := range pkg.GoFiles {
-		file := ft.NewFileInfo(pkg.Dir+"/"+source, pkg)
+	sources := []*FileInfo{}
+	for _, source := range pkg.SourceFiles() {
+		sources = append(sources, ft.NewFileInfo(pkg.Dir+"/"+source, pkg))
+	}
+	ft.CheckPackage(pkg, sources)
+	for _, file := range sources {
 		// Add instrumentation code to compute coverage
 		file.addInstrumentationGo()
 		file.writeInstrumentation()
@@ -56,15 +85,28 @@ func (ft *FileTable) InstrumentPackage(pkg *PackageInfo) {
 		file.addInstrumentationTEST()
 		file.writeInstrumentation()
 	}
+	// A main package can't be imported by its external tests
+	if len(pkg.XTestGoFiles) > 0 && pkg.Name != "main" {
+		export := pkg.Dir + "/" + REACH_EXPORT_FILE
+		err := os.WriteFile(export, []byte(fmt.Sprintf(REACH_EXPORT_DEFINITION, pkg.Name)), 0777)
+		if err != nil {
+			panic(err)
+		}
+		ft.Generated = append(ft.Generated, export)
 
-	// Run tests of package to get coverage data
-	Verbosef("computing coverage >> go test " + fmt.Sprintf("%s/%s", TMP_ROOT, pkg.ImportPath))
-	os.Chdir(TMP_ROOT)
-	out, err := exec.Command("pwd").Output()
-	if err == nil {
-		Verbosef("PWD " + string(out))
+		for _, source := range pkg.XTestGoFiles {
+			file := ft.NewFileInfo(pkg.Dir+"/"+source, pkg)
+			file.External = true
+			file.addInstrumentationTEST()
+			file.writeInstrumentation()
+		}
 	}
-	_, err = exec.Command("go", "test", pkg.ImportPath).Output()
+
+	// Run tests of package to get coverage data
+	Verbosef("computing coverage >> AT (%s) go test %s\n", pkg.ModuleRoot(), pkg.ImportPath)
+	cmd := exec.Command("go", "test", pkg.ImportPath)
+	cmd.Dir = pkg.ModuleRoot()
+	_, err := cmd.Output()
 	if err != nil {
 		if exit, ok := err.(*exec.ExitError); ok {
 			fmt.Println(string(exit.Stderr))
@@ -73,8 +115,8 @@ func (ft *FileTable) InstrumentPackage(pkg *PackageInfo) {
 	}
 }
 
-func (file *FileInfo) addInstrumentationGo() {
-	visited := make(map[ast.Node]bool)
+// Finds the mutations of the file, scoped by the block that encloses them
+func (file *FileInfo) addMutations() {
 	path := []ast.Node{}
 	astWalk := func(node ast.Node) (ret bool) {
 		ret = true
@@ -85,8 +127,7 @@ func (file *FileInfo) addInstrumentationGo() {
 		}
 		path = append(path, node)
 
-		// If this node has mutations we will instrument the enclosing block to check reachability at runtime
-		changes := mutations(string(file.Source), node, path, DEFAULT_MUTATORS)
+		changes := mutations(string(file.Source), node, path, file.Info, DEFAULT_MUTATORS)
 		// if len(changes) == 0 {
 		// 	return
 		// }
@@ -100,68 +141,103 @@ func (file *FileInfo) addInstrumentationGo() {
 		muts := []*Mutation{}
 		for _, change := range changes {
 			// Add the mutation with the actual location
-			muts = append(muts, &Mutation{file, change, parentNode.Pos(), false})
+			muts = append(muts, &Mutation{File: file, Change: change, Pos: parentNode.Pos()})
 		}
 
 		// The mutation being scoped by parent block makes it easier to retrieve info later
 		m := file.Mutations[parentNode.Pos()]
 		file.Mutations[parentNode.Pos()] = append(m, muts...)
 
-		// Here we add the coverage code (only needs to be done once per block)
-		if !visited[parentNode] {
-			visited[parentNode] = true
-			// Create __reach("BLOCK_ID:FILEPATH") call
-			reach := fmt.Sprintf(`__reach("R %d:%d", false);`, file.Id, parentNode.Pos())
-
-			file.addSourceChange(&SourceChange{SC_APPEND, reach, parentNode.End()}, at)
+		if file.Blocks[parentNode.Pos()] == nil {
+			file.Blocks[parentNode.Pos()] = &Block{parentNode, at}
 		}
 		return
 	}
 	ast.Inspect(file.AST, astWalk)
 }
 
+func (file *FileInfo) addInstrumentationGo() {
+	file.addMutations()
+
+	// If a block has mutations we instrument it to check reachability at runtime
+	for pos, block := range file.Blocks {
+		// Create __reach("BLOCK_ID:FILEPATH") call
+		reach := fmt.Sprintf(`__reach("R %d:%d", false);`, file.Id, pos)
+
+		file.addSourceChange(&SourceChange{SC_APPEND, reach, block.Node.End()}, block.At)
+	}
+}
+
+// Marks the start of each test, subtests started with t.Run also mark their end
+// so the blocks reached after them are attributed back to the parent test
 func (file *FileInfo) addInstrumentationTEST() {
-	astWalk := func(n ast.Node) bool {
-		if _, ok := n.(*ast.File); ok {
-			return true
-		}
-		if fun, ok := n.(*ast.FuncDecl); !ok || len(fun.Type.Params.List) < 1 {
-			return false
+	for _, decl := range file.AST.Decls {
+		fun, ok := decl.(*ast.FuncDecl)
+		if !ok || fun.Recv != nil || fun.Body == nil || !strings.HasPrefix(fun.Name.Name, "Test") || testParam(fun.Type) == nil {
+			continue
 		}
-		fun := n.(*ast.FuncDecl)
-		fields := fun.Type.Params.List
-		hasTest := false
-		// FIXME: REDUNDANT FOR
-		if len(fields) > 1 {
-			return false
-		}
-		for _, f := range fields {
-			e, ok := f.Type.(*ast.StarExpr)
-			if !ok {
-				continue
+
+		reach := fmt.Sprintf(`%s("T %s %s", true);`, file.reachFunc(), file.Package.ImportPath, fun.Name.Name)
+		file.addSourceChange(&SourceChange{SC_APPEND, reach, fun.Body.Rbrace + 1}, fun.Body.Lbrace+1)
+
+		ast.Inspect(fun.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
 			}
-			s, ok := e.X.(*ast.SelectorExpr)
-			if !ok {
-				continue
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Run" {
+				return true
 			}
-			i, ok := s.X.(*ast.Ident)
+			lit, ok := call.Args[1].(*ast.FuncLit)
 			if !ok {
-				continue
+				return true
 			}
-			if i.Name == "testing" && s.Sel.Name == "T" {
-				hasTest = true
-				break
+
+			// The subtest name is only known at runtime
+			param := testParam(lit.Type)
+			if param == nil || len(param.Names) != 1 || param.Names[0].Name == "_" {
+				return true
 			}
-		}
-		if hasTest {
-			reach := fmt.Sprintf(`__reach("T %d:%d", true)`, file.Id, fun.Pos())
-			// reach, _ := parser.ParseExpr(reachSrc)
-			// fun.Body.List = append([]ast.Stmt{&ast.ExprStmt{X: reach}}, fun.Body.List...)
-			file.addSourceChange(&SourceChange{SC_APPEND, reach, fun.Body.Rbrace + 1}, fun.Body.Lbrace+1)
-		}
-		return false
+			t := param.Names[0].Name
+			reach := fmt.Sprintf(
+				`%[1]s("T %[2]s "+%[3]s.Name(), true); defer %[1]s("E %[2]s "+%[3]s.Name(), true);`,
+				file.reachFunc(), file.Package.ImportPath, t,
+			)
+			file.addSourceChange(&SourceChange{SC_APPEND, reach, lit.Body.Rbrace + 1}, lit.Body.Lbrace+1)
+			return true
+		})
 	}
-	ast.Inspect(file.AST, astWalk)
+}
+
+// Returns the parameter of a function that only receives a *testing.T
+func testParam(fun *ast.FuncType) *ast.Field {
+	fields := fun.Params.List
+	if len(fields) != 1 || len(fields[0].Names) > 1 {
+		return nil
+	}
+
+	e, ok := fields[0].Type.(*ast.StarExpr)
+	if !ok {
+		return nil
+	}
+	s, ok := e.X.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	i, ok := s.X.(*ast.Ident)
+	if !ok || i.Name != "testing" || s.Sel.Name != "T" {
+		return nil
+	}
+	return fields[0]
+}
+
+// External test files can only call __reach through the exported wrapper
+func (file *FileInfo) reachFunc() string {
+	if file.External {
+		return REACH_EXPORT_IMPORT + ".GolangMutReach"
+	}
+	return "__reach"
 }
 
 func (file *FileInfo) writeInstrumentation() {
@@ -172,7 +248,9 @@ func (file *FileInfo) writeInstrumentation() {
 
 	writer := bytes.Buffer{}
 	for i := 0; i < len(file.Source); i++ {
-		if i == int(file.AST.Name.End()) && !file.Imports[`"os"`] && !file.Package.ReachDefined {
+		if i == int(file.AST.Name.End()) && file.External {
+			fmt.Fprintf(&writer, "\nimport %s %q", REACH_EXPORT_IMPORT, file.Package.ImportPath)
+		} else if i == int(file.AST.Name.End()) && !file.Imports[`"os"`] && !file.Package.ReachDefined {
 			fmt.Fprint(&writer, "\n"+`import "os"`)
 		}
 
@@ -187,7 +265,7 @@ func (file *FileInfo) writeInstrumentation() {
 		writer.WriteByte(file.Source[i])
 	}
 
-	if !file.Package.ReachDefined {
+	if !file.Package.ReachDefined && !file.External {
 		fmt.Fprintf(&writer, REACH_DEFINITION, TMP_ROOT)
 		file.Package.ReachDefined = true
 	}
@@ -0,0 +1,211 @@
+// Concurrency (CON) mutators, they break the synchronization between goroutines
+package main
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// Methods that start a critical section, along with the method that ends it
+var LOCK_METHODS = map[string]string{
+	"(*sync.Mutex).Lock":    "Unlock",
+	"(*sync.RWMutex).Lock":  "Unlock",
+	"(*sync.RWMutex).RLock": "RUnlock",
+	"(sync.Locker).Lock":    "Unlock",
+}
+
+type CONRemoveLock struct{}
+
+// Removes a lock along with the first unlock of the same mutex in the statement list, deferred or not.
+// Both statements are replaced at once, so the mutation spans from the lock to the unlock
+func (CONRemoveLock) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	lock, ok := orig.(*ast.ExprStmt)
+	if !ok || len(path) < 2 {
+		return nil
+	}
+	call, ok := lock.X.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	unlockName, ok := LOCK_METHODS[methodName(call, info)]
+	if !ok {
+		return nil
+	}
+	mutex := receiver(source, call)
+
+	list := stmtList(path[len(path)-2])
+	for i, stmt := range list {
+		if stmt != lock {
+			continue
+		}
+		for _, next := range list[i+1:] {
+			var unlock *ast.CallExpr
+			switch s := next.(type) {
+			case *ast.ExprStmt:
+				unlock, _ = s.X.(*ast.CallExpr)
+			case *ast.DeferStmt:
+				unlock = s.Call
+			}
+			if unlock == nil || receiver(source, unlock) != mutex {
+				continue
+			}
+			if sel, ok := unlock.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == unlockName {
+				span := &ast.BlockStmt{Lbrace: lock.Pos(), Rbrace: next.End() - 1}
+				return &Replacement{"CONRemoveLock", lock, span, source[lock.End():next.Pos()], source[lock.Pos():next.End()]}
+			}
+		}
+	}
+	return nil
+}
+
+type CONRemoveClose struct{}
+
+func (CONRemoveClose) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, call := callStmt(orig)
+	if call == nil || !isBuiltin(call.Fun, "close", info) {
+		return nil
+	}
+	return statementDeletion("CONRemoveClose", source, path, stmt)
+}
+
+// Whether the call is removed by CONRemoveClose, CONRemoveDone or CONRemoveAdd
+func isConcurrencyCall(call *ast.CallExpr, info *types.Info) bool {
+	switch methodName(call, info) {
+	case "(*sync.WaitGroup).Done", "(*sync.WaitGroup).Add":
+		return true
+	}
+	return isBuiltin(call.Fun, "close", info)
+}
+
+type CONGoToSync struct{}
+
+// go f() => f()
+func (CONGoToSync) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.GoStmt)
+	if !ok {
+		return nil
+	}
+	return &Replacement{"CONGoToSync", stmt, stmt, source[stmt.Call.Pos():stmt.Call.End()], source[stmt.Pos():stmt.End()]}
+}
+
+type CONRemoveDone struct{}
+
+func (CONRemoveDone) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, call := callStmt(orig)
+	if call == nil || methodName(call, info) != "(*sync.WaitGroup).Done" {
+		return nil
+	}
+	return statementDeletion("CONRemoveDone", source, path, stmt)
+}
+
+type CONRemoveAdd struct{}
+
+func (CONRemoveAdd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, call := callStmt(orig)
+	if call == nil || methodName(call, info) != "(*sync.WaitGroup).Add" {
+		return nil
+	}
+	return statementDeletion("CONRemoveAdd", source, path, stmt)
+}
+
+type CONBufferedToUnbuffered struct{}
+
+// make(chan T, n) => make(chan T)
+func (CONBufferedToUnbuffered) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	call := makeChan(orig, info)
+	if call == nil || len(call.Args) != 2 {
+		return nil
+	}
+	return makeChanReplacement("CONBufferedToUnbuffered", source, path, call, call.Args[:1])
+}
+
+type CONUnbufferedToBuffered struct{}
+
+// make(chan T) => make(chan T, 1)
+func (CONUnbufferedToBuffered) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	call := makeChan(orig, info)
+	if call == nil || len(call.Args) != 1 {
+		return nil
+	}
+	return makeChanReplacement("CONUnbufferedToBuffered", source, path, call, []ast.Expr{call.Args[0], &ONE})
+}
+
+func makeChanReplacement(issuer string, source string, path []ast.Node, call *ast.CallExpr, args []ast.Expr) *Replacement {
+	stmt := RootStmt(path)
+	if stmt == nil {
+		return nil
+	}
+
+	new := &ast.CallExpr{Fun: call.Fun, Args: args}
+	oldStr, newStr := MutationString(source, stmt, call, new)
+	return &Replacement{issuer, call, stmt, newStr, oldStr}
+}
+
+// Call to make for a channel type
+func makeChan(orig ast.Node, info *types.Info) *ast.CallExpr {
+	call, ok := orig.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 || !isBuiltin(call.Fun, "make", info) {
+		return nil
+	}
+	if typ := info.TypeOf(call.Args[0]); typ == nil {
+		return nil
+	} else if _, ok := typ.Underlying().(*types.Chan); !ok {
+		return nil
+	}
+	return call
+}
+
+// Call made by an expression statement or a defer
+func callStmt(orig ast.Node) (ast.Stmt, *ast.CallExpr) {
+	switch stmt := orig.(type) {
+	case *ast.ExprStmt:
+		if call, ok := stmt.X.(*ast.CallExpr); ok {
+			return stmt, call
+		}
+	case *ast.DeferStmt:
+		return stmt, stmt.Call
+	}
+	return nil, nil
+}
+
+// Full name of the called method, like (*sync.Mutex).Lock, empty for other calls
+func methodName(call *ast.CallExpr, info *types.Info) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || info == nil {
+		return ""
+	}
+	if fun, ok := info.Uses[sel.Sel].(*types.Func); ok {
+		return fun.FullName()
+	}
+	return ""
+}
+
+// Source of the value a method is called on
+func receiver(source string, call *ast.CallExpr) string {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		return source[sel.X.Pos():sel.X.End()]
+	}
+	return ""
+}
+
+func isBuiltin(fun ast.Expr, name string, info *types.Info) bool {
+	ident, ok := fun.(*ast.Ident)
+	if !ok || ident.Name != name || info == nil {
+		return false
+	}
+	_, ok = info.Uses[ident].(*types.Builtin)
+	return ok
+}
+
+// Statements of a block or clause
+func stmtList(node ast.Node) []ast.Stmt {
+	switch n := node.(type) {
+	case *ast.BlockStmt:
+		return n.List
+	case *ast.CaseClause:
+		return n.Body
+	case *ast.CommClause:
+		return n.Body
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Context (CTX) and time (TIME) mutators, they break context propagation and change durations
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+)
+
+type CTXBackground struct{}
+
+// A context passed to a call is replaced, so cancellations and deadlines no longer reach it: f(ctx) => f(context.Background())
+func (CTXBackground) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	arg, ok := orig.(ast.Expr)
+	if !ok || info == nil || len(path) < 2 || !isNamed(info.TypeOf(arg), "context", "Context") {
+		return nil
+	}
+	call, ok := path[len(path)-2].(*ast.CallExpr)
+	if !ok || call.Fun == arg {
+		return nil
+	}
+	if inner, ok := arg.(*ast.CallExpr); ok {
+		switch methodName(inner, info) {
+		case "context.Background", "context.TODO":
+			return nil
+		}
+	}
+
+	file := path[0].(*ast.File)
+	name := importName(file, "context", "context")
+	if name == "" || soleUse(arg, file, info) {
+		return nil
+	}
+	return exprReplacement("CTXBackground", source, path, arg, name+".Background()")
+}
+
+// Whether the expression is the only use of a local variable, which would be left unused without it
+func soleUse(expr ast.Expr, file *ast.File, info *types.Info) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Obj == nil || file.Scope.Lookup(ident.Name) == ident.Obj {
+		return false
+	}
+	switch ident.Obj.Decl.(type) {
+	case *ast.AssignStmt, *ast.ValueSpec:
+	default:
+		return false
+	}
+
+	obj := info.Uses[ident]
+	uses := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		if other, ok := node.(*ast.Ident); ok && info.Uses[other] == obj {
+			uses++
+		}
+		return true
+	})
+	return uses == 1
+}
+
+type CTXRemoveCancel struct{}
+
+// defer cancel() is removed, so the context is only released when its parent is
+func (CTXRemoveCancel) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.DeferStmt)
+	if !ok || !isCancel(stmt.Call, info) {
+		return nil
+	}
+	return statementDeletion("CTXRemoveCancel", source, path, stmt)
+}
+
+func isCancel(call *ast.CallExpr, info *types.Info) bool {
+	if info == nil {
+		return false
+	}
+	typ := info.TypeOf(call.Fun)
+	return isNamed(typ, "context", "CancelFunc") || isNamed(typ, "context", "CancelCauseFunc")
+}
+
+type CTXRemoveErrCheck struct{}
+
+// The check of ctx.Err() is removed like ERRRemoveCheck does, so the code goes on after the context is done
+func (CTXRemoveErrCheck) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.IfStmt)
+	if !ok || !isContextCheck(stmt, info) {
+		return nil
+	}
+	return checkRemoval("CTXRemoveErrCheck", source, stmt)
+}
+
+// Whether the init statement or the condition of the if statement calls ctx.Err()
+func isContextCheck(stmt *ast.IfStmt, info *types.Info) bool {
+	found := false
+	for _, node := range []ast.Node{stmt.Init, stmt.Cond} {
+		if node == nil {
+			continue
+		}
+		ast.Inspect(node, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && methodName(call, info) == "(context.Context).Err" {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+type TIMEMultiply struct{}
+
+// Constant durations are ten times longer: 5 * time.Second => (5 * time.Second) * 10
+func (TIMEMultiply) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, value := durationConstant(orig, path, info)
+	if expr == nil {
+		return nil
+	}
+	if constant.Compare(constant.BinaryOp(value, token.MUL, constant.MakeInt64(10)), token.GTR, constant.MakeInt64(math.MaxInt64)) {
+		return nil
+	}
+	return exprReplacement("TIMEMultiply", source, path, expr, "("+source[expr.Pos():expr.End()]+") * 10")
+}
+
+type TIMEShorten struct{}
+
+// Constant durations are ten times shorter: 5 * time.Second => (5 * time.Second) / 10
+func (TIMEShorten) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, _ := durationConstant(orig, path, info)
+	if expr == nil {
+		return nil
+	}
+	return exprReplacement("TIMEShorten", source, path, expr, "("+source[expr.Pos():expr.End()]+") / 10")
+}
+
+// Whole constant expression of type time.Duration, like time.Second or 5 * time.Second.
+// Named constants are mutated where they are declared instead of where they are used
+func durationConstant(orig ast.Node, path []ast.Node, info *types.Info) (ast.Expr, constant.Value) {
+	expr, ok := orig.(ast.Expr)
+	if !ok || info == nil || len(path) < 2 {
+		return nil, nil
+	}
+	if _, ok := expr.(*ast.Ident); ok {
+		return nil, nil
+	}
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || !isNamed(tv.Type, "time", "Duration") || constant.Sign(tv.Value) == 0 {
+		return nil, nil
+	}
+	if parent, ok := path[len(path)-2].(ast.Expr); ok && info.Types[parent].Value != nil {
+		return nil, nil
+	}
+	return expr, tv.Value
+}
+
+// Whether the type is the named type pkg.name
+func isNamed(typ types.Type, pkg string, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == pkg && named.Obj().Name() == name
+}
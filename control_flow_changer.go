@@ -0,0 +1,157 @@
+// Control flow (CF) mutators, they change which branches are taken and how often loops run
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+type CFRemoveElse struct{}
+
+// The else branch is emptied, it only keeps the variables that were used by it.
+// An if-else that makes the function terminate is kept, without the else it would be missing a return
+func (CFRemoveElse) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.IfStmt)
+	if !ok || stmt.Else == nil || isTerminatingStmt(path, len(path)-1) {
+		return nil
+	}
+
+	newStr := source[stmt.Pos():stmt.Body.End()]
+	if vars := usedVariables(stmt.Else); len(vars) > 0 {
+		newStr += " else { " + strings.Repeat("_, ", len(vars)-1) + "_ = " + strings.Join(vars, ", ") + " }"
+	}
+	return &Replacement{"CFRemoveElse", stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+type CFIfTrue struct{}
+
+func (CFIfTrue) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	cond := ifCondition(orig, path)
+	if cond == nil {
+		return nil
+	}
+	return constantCondition("CFIfTrue", source, path, cond, "true")
+}
+
+type CFIfFalse struct{}
+
+func (CFIfFalse) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	cond := ifCondition(orig, path)
+	if cond == nil {
+		return nil
+	}
+	return constantCondition("CFIfFalse", source, path, cond, "false")
+}
+
+// Condition of an if statement, comparisons are left to RORConditionTrue and RORConditionFalse
+func ifCondition(orig ast.Node, path []ast.Node) ast.Expr {
+	if len(path) < 2 {
+		return nil
+	}
+	stmt, ok := path[len(path)-2].(*ast.IfStmt)
+	if !ok || stmt.Cond != orig {
+		return nil
+	}
+
+	if binary, ok := stmt.Cond.(*ast.BinaryExpr); ok {
+		switch binary.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return nil
+		}
+	}
+	return stmt.Cond
+}
+
+type CFBreakToContinue struct{}
+
+func (CFBreakToContinue) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.BranchStmt)
+	if !ok || stmt.Tok != token.BREAK || !inLoop(stmt, path) {
+		return nil
+	}
+	return branchReplacement("CFBreakToContinue", source, stmt, token.CONTINUE)
+}
+
+type CFContinueToBreak struct{}
+
+func (CFContinueToBreak) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.BranchStmt)
+	if !ok || stmt.Tok != token.CONTINUE {
+		return nil
+	}
+	return branchReplacement("CFContinueToBreak", source, stmt, token.BREAK)
+}
+
+func branchReplacement(issuer string, source string, stmt *ast.BranchStmt, tok token.Token) *Replacement {
+	newStr := tok.String()
+	if stmt.Label != nil {
+		newStr += " " + stmt.Label.Name
+	}
+	return &Replacement{issuer, stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+// Whether a continue can replace the break, it must be inside of the loop the break refers to
+func inLoop(stmt *ast.BranchStmt, path []ast.Node) bool {
+	for i := len(path) - 1; i >= 0; i-- {
+		switch node := path[i].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return false
+		case *ast.LabeledStmt:
+			if stmt.Label != nil && node.Label.Name == stmt.Label.Name {
+				switch node.Stmt.(type) {
+				case *ast.ForStmt, *ast.RangeStmt:
+					return true
+				}
+				return false
+			}
+		case *ast.ForStmt, *ast.RangeStmt:
+			if stmt.Label == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type CFLoopZero struct{}
+
+// The body starts with a break, so the loop runs zero times
+func (CFLoopZero) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, body := loopBody(orig, path)
+	if stmt == nil {
+		return nil
+	}
+
+	newStr := source[stmt.Pos():body.Lbrace+1] + " break; " + source[body.Lbrace+1:stmt.End()]
+	return &Replacement{"CFLoopZero", stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+type CFLoopOnce struct{}
+
+// The body ends with a break, so the loop runs once. A continue in the body still skips the break
+func (CFLoopOnce) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, body := loopBody(orig, path)
+	if stmt == nil {
+		return nil
+	}
+
+	newStr := source[stmt.Pos():body.Rbrace] + "; break }" + source[body.Rbrace+1:stmt.End()]
+	return &Replacement{"CFLoopOnce", stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+// Loops that can be broken out of. A for without condition that makes the function terminate
+// is left out, with a break the function would be missing a return
+func loopBody(orig ast.Node, path []ast.Node) (ast.Stmt, *ast.BlockStmt) {
+	switch stmt := orig.(type) {
+	case *ast.ForStmt:
+		if stmt.Cond == nil && isTerminatingStmt(path, len(path)-1) {
+			return nil, nil
+		}
+		return stmt, stmt.Body
+	case *ast.RangeStmt:
+		return stmt, stmt.Body
+	}
+	return nil, nil
+}
@@ -0,0 +1,195 @@
+// The goal of this step is to compute reachability from go's own coverage, without changing the code
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const COVER_DIR = ".golang-mut/cover"
+
+// Builds the same (blockLocation => tests[]) map as ParseCoverage.
+// Each package gets a test binary built with -cover over every analysed package,
+// then each of its tests is run alone with -test.coverprofile
+func (ft *FileTable) CollectCoverage(packages []PackageInfo) map[NodeIdentifier][]TestIdentifier {
+	tested := []*PackageInfo{}
+	coverpkg := []string{}
+	for i := range packages {
+		pkg := &packages[i]
+		// If the package has no tests, or is empty: skip
+		if len(pkg.TestGoFiles) == 0 && len(pkg.XTestGoFiles) == 0 {
+			fmt.Printf("?\t%s\t[no test files]\n", pkg.ImportPath)
+			continue
+		} else if len(pkg.SourceFiles()) == 0 {
+			fmt.Printf("?\t%s\t[no .go files]\n", pkg.ImportPath)
+			continue
+		}
+
+		sources := []*FileInfo{}
+		for _, source := range pkg.SourceFiles() {
+			sources = append(sources, ft.NewFileInfo(pkg.Dir+"/"+source, pkg))
+		}
+		ft.CheckPackage(pkg, sources)
+		for _, file := range sources {
+			file.addMutations()
+		}
+		tested = append(tested, pkg)
+		coverpkg = append(coverpkg, pkg.ImportPath)
+	}
+
+	dir := filepath.Join(TMP_ROOT, COVER_DIR)
+	err := os.MkdirAll(dir, 0777)
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	profiles := newProfileReader(ft)
+	testsPerBlock := make(map[NodeIdentifier][]TestIdentifier)
+	for _, pkg := range tested {
+		bin := filepath.Join(dir, strings.ReplaceAll(pkg.ImportPath, "/", "_")+".test")
+		Verbosef("computing coverage >> AT (%s) go test -c -cover %s\n", pkg.ModuleRoot(), pkg.ImportPath)
+		cmd := exec.Command("go", "test", "-c", "-cover", "-covermode=set", "-coverpkg="+strings.Join(coverpkg, ","), "-o", bin, pkg.ImportPath)
+		cmd.Dir = pkg.ModuleRoot()
+		mustOutput(cmd)
+
+		for _, name := range listTests(bin, pkg.Dir) {
+			profile := filepath.Join(dir, "cover.out")
+			cmd := exec.Command(bin, "-test.run", "^"+regexp.QuoteMeta(name)+"$", "-test.coverprofile", profile)
+			cmd.Dir = pkg.Dir
+			mustOutput(cmd)
+
+			test := TestIdentifier{pkg.ImportPath, name}
+			for _, block := range profiles.Read(profile) {
+				testsPerBlock[block] = append(testsPerBlock[block], test)
+			}
+		}
+	}
+	return testsPerBlock
+}
+
+// Output of a command that must succeed
+func mustOutput(cmd *exec.Cmd) []byte {
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			fmt.Println(string(out) + string(exit.Stderr))
+		}
+		panic(err)
+	}
+	return out
+}
+
+// Top level tests of a test binary
+func listTests(bin string, dir string) []string {
+	cmd := exec.Command(bin, "-test.list", ".")
+	cmd.Dir = dir
+	tests := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(mustOutput(cmd)))
+	for scanner.Scan() {
+		// Benchmarks, fuzz targets and examples are listed as well
+		if name := scanner.Text(); strings.HasPrefix(name, "Test") {
+			tests = append(tests, name)
+		}
+	}
+	return tests
+}
+
+// Maps the ranges of coverage profiles back to the blocks of the FileTable
+type profileReader struct {
+	// Files indexed by the name used in profiles, import path + file name
+	files map[string]*FileInfo
+	// Offset of each line of a file
+	lines map[*FileInfo][]int
+}
+
+func newProfileReader(ft *FileTable) *profileReader {
+	reader := &profileReader{make(map[string]*FileInfo), make(map[*FileInfo][]int)}
+	for _, file := range ft.Files {
+		reader.files[file.Package.ImportPath+"/"+filepath.Base(file.Path)] = file
+	}
+	return reader
+}
+
+// Blocks with at least one covered range in the profile.
+// Each line of a profile looks like: example.com/pkg/file.go:10.24,12.2 1 1
+func (r *profileReader) Read(profile string) []NodeIdentifier {
+	content, err := os.ReadFile(profile)
+	if err != nil {
+		panic(err)
+	}
+
+	reached := make(map[NodeIdentifier]bool)
+	blocks := []NodeIdentifier{}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[2] == "0" {
+			continue
+		}
+
+		colon := strings.LastIndex(fields[0], ":")
+		file := r.files[fields[0][:colon]]
+		if file == nil {
+			continue
+		}
+
+		var startLine, startCol int
+		fmt.Sscanf(fields[0][colon+1:], "%d.%d,", &startLine, &startCol)
+		block := r.innermostBlock(file, r.offset(file, startLine, startCol))
+		if block == nil {
+			continue
+		}
+
+		id := NodeIdentifier{file.Id, int(block.Node.Pos())}
+		if !reached[id] {
+			reached[id] = true
+			blocks = append(blocks, id)
+		}
+	}
+	return blocks
+}
+
+// Byte offset of a line and column, both starting at 1
+func (r *profileReader) offset(file *FileInfo, line int, col int) int {
+	if r.lines[file] == nil {
+		lines := []int{0}
+		for i, c := range file.Source {
+			if c == '\n' {
+				lines = append(lines, i+1)
+			}
+		}
+		r.lines[file] = lines
+	}
+
+	lines := r.lines[file]
+	if line < 1 || line > len(lines) {
+		return -1
+	}
+	return lines[line-1] + col - 1
+}
+
+// Smallest block of the file that contains the offset.
+// A covered range starts at the opening brace or after the colon of its block
+func (r *profileReader) innermostBlock(file *FileInfo, offset int) *Block {
+	blocks := []*Block{}
+	for _, block := range file.Blocks {
+		if int(block.Node.Pos()) <= offset && offset <= int(block.Node.End()) {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Node.End()-blocks[i].Node.Pos() < blocks[j].Node.End()-blocks[j].Node.Pos()
+	})
+	return blocks[0]
+}
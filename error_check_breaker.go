@@ -0,0 +1,98 @@
+// Error handling (ERR) mutators, they break the if err != nil checks
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+type ERRRemoveCheck struct{}
+
+// The check is replaced by a block that keeps its init statement, the variables it used and its else branch:
+// if err := f(); err != nil { return err } => { err := f(); _ = err }
+func (ERRRemoveCheck) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	// Checks of ctx.Err() are left to CTXRemoveErrCheck
+	stmt, ok := orig.(*ast.IfStmt)
+	if !ok || !isErrorCheck(stmt, info) || isContextCheck(stmt, info) {
+		return nil
+	}
+	return checkRemoval("ERRRemoveCheck", source, stmt)
+}
+
+// Replaces the if statement by a block with everything it had except for its body
+func checkRemoval(issuer string, source string, stmt *ast.IfStmt) *Replacement {
+	parts := []string{}
+	vars := usedVariables(stmt)
+	if stmt.Init != nil {
+		parts = append(parts, source[stmt.Init.Pos():stmt.Init.End()])
+		if assign, ok := stmt.Init.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+					vars = append(vars, ident.Name)
+				}
+			}
+		}
+	}
+	if len(vars) > 0 {
+		parts = append(parts, strings.Repeat("_, ", len(vars)-1)+"_ = "+strings.Join(vars, ", "))
+	}
+	// Only the error branch is removed
+	if stmt.Else != nil {
+		parts = append(parts, source[stmt.Else.Pos():stmt.Else.End()])
+	}
+
+	newStr := "{ " + strings.Join(parts, "; ") + " }"
+	return &Replacement{issuer, stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+type ERRInvertCheck struct{}
+
+func (ERRInvertCheck) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	if !isErrorCheckCond(orig, path, info) {
+		return nil
+	}
+	return operatorReplacement("ERRInvertCheck", source, path, info, orig, token.NEQ, token.EQL)
+}
+
+// Whether the node is the condition of an if err != nil check
+func isErrorCheckCond(orig ast.Node, path []ast.Node, info *types.Info) bool {
+	if len(path) < 2 {
+		return false
+	}
+	parent, ok := path[len(path)-2].(*ast.IfStmt)
+	return ok && parent.Cond == orig && isErrorCheck(parent, info)
+}
+
+type ERRSwallow struct{}
+
+// A return directly inside the check falls through instead, as if the error never happened
+func (ERRSwallow) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.ReturnStmt)
+	if !ok || len(path) < 3 {
+		return nil
+	}
+	check, ok := path[len(path)-3].(*ast.IfStmt)
+	if !ok || check.Body != path[len(path)-2] || !isErrorCheck(check, info) || isTerminatingStmt(path, len(path)-1) {
+		return nil
+	}
+	return statementDeletion("ERRSwallow", source, path, stmt)
+}
+
+// Whether the condition of the if statement is <error> != nil
+func isErrorCheck(stmt *ast.IfStmt, info *types.Info) bool {
+	cond, ok := stmt.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.NEQ || info == nil {
+		return false
+	}
+
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	for _, sides := range [][2]ast.Expr{{cond.X, cond.Y}, {cond.Y, cond.X}} {
+		typ := info.TypeOf(sides[0])
+		if typ != nil && info.Types[sides[1]].IsNil() && types.Implements(typ, errorType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+// Slice (SLC) and map (MAP) mutators, they shift slice bounds by one and change what map lookups find
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+)
+
+type SLCLowInc struct{}
+
+// s[i:j] => s[i + 1:j], a missing low bound becomes 1
+func (SLCLowInc) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, ok := orig.(*ast.SliceExpr)
+	if !ok || info == nil {
+		return nil
+	}
+
+	low, lowConst := int64(0), true
+	newLow := "1"
+	if expr.Low != nil {
+		low, lowConst = constantInt(expr.Low, info)
+		newLow = source[expr.Low.Pos():expr.Low.End()] + " + 1"
+	}
+	if high, ok := constantInt(expr.High, info); ok && lowConst && low+1 > high {
+		return nil
+	}
+	return sliceReplacement("SLCLowInc", source, path, expr, newLow, boundString(source, expr.High))
+}
+
+type SLCLowDec struct{}
+
+// s[i:j] => s[i - 1:j]
+func (SLCLowDec) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, ok := orig.(*ast.SliceExpr)
+	if !ok || expr.Low == nil || info == nil {
+		return nil
+	}
+	if low, ok := constantInt(expr.Low, info); ok && low < 1 {
+		return nil
+	}
+
+	newLow := source[expr.Low.Pos():expr.Low.End()] + " - 1"
+	return sliceReplacement("SLCLowDec", source, path, expr, newLow, boundString(source, expr.High))
+}
+
+type SLCHighInc struct{}
+
+// s[i:j] => s[i:j + 1]
+func (SLCHighInc) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, ok := orig.(*ast.SliceExpr)
+	if !ok || expr.High == nil || info == nil {
+		return nil
+	}
+	// Constant bounds are checked against the length of arrays when compiling
+	if _, ok := constantInt(expr.High, info); ok && isArray(info.TypeOf(expr.X)) {
+		return nil
+	}
+
+	newHigh := source[expr.High.Pos():expr.High.End()] + " + 1"
+	return sliceReplacement("SLCHighInc", source, path, expr, boundString(source, expr.Low), newHigh)
+}
+
+type SLCHighDec struct{}
+
+// s[i:j] => s[i:j - 1], a missing high bound becomes len(s) - 1 when s is a variable
+func (SLCHighDec) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, ok := orig.(*ast.SliceExpr)
+	if !ok || info == nil {
+		return nil
+	}
+
+	var newHigh string
+	if expr.High == nil {
+		ident, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		newHigh = "len(" + ident.Name + ") - 1"
+	} else {
+		low, lowConst := int64(0), true
+		if expr.Low != nil {
+			low, lowConst = constantInt(expr.Low, info)
+		}
+		if high, ok := constantInt(expr.High, info); ok && (high < 1 || (lowConst && high-1 < low)) {
+			return nil
+		}
+		newHigh = source[expr.High.Pos():expr.High.End()] + " - 1"
+	}
+	return sliceReplacement("SLCHighDec", source, path, expr, boundString(source, expr.Low), newHigh)
+}
+
+func sliceReplacement(issuer string, source string, path []ast.Node, expr *ast.SliceExpr, low string, high string) *Replacement {
+	value := source[expr.Pos():expr.Lbrack+1] + low + ":" + high
+	if expr.Slice3 {
+		value += ":" + boundString(source, expr.Max)
+	}
+	return exprReplacement(issuer, source, path, expr, value+"]")
+}
+
+func boundString(source string, bound ast.Expr) string {
+	if bound == nil {
+		return ""
+	}
+	return source[bound.Pos():bound.End()]
+}
+
+// Value of a constant integer expression, false if it isn't constant
+func constantInt(expr ast.Expr, info *types.Info) (int64, bool) {
+	if expr == nil {
+		return 0, false
+	}
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil {
+		return 0, false
+	}
+	return constant.Int64Val(constant.ToInt(tv.Value))
+}
+
+func isArray(typ types.Type) bool {
+	if typ == nil {
+		return false
+	}
+	if pointer, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = pointer.Elem()
+	}
+	_, ok := typ.Underlying().(*types.Array)
+	return ok
+}
+
+type MAPZeroRead struct{}
+
+// The lookup finds nothing, so it reads the zero value of the elements: m[k] => 0
+func (MAPZeroRead) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr := mapIndex(orig, info)
+	if expr == nil || !isRead(expr, path, info) || untypedContext(path, info) {
+		return nil
+	}
+	// Returned values are already replaced by the RET mutators
+	if ret, _ := returnedValue(expr, path, info); ret != nil {
+		return nil
+	}
+	return exprReplacement("MAPZeroRead", source, path, expr, zeroValue(info.TypeOf(expr), path[0].(*ast.File), info))
+}
+
+type MAPDropOk struct{}
+
+// The lookup always reports the key as found: v, ok := m[k] => v, ok := m[k], true
+func (MAPDropOk) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.AssignStmt)
+	if !ok || len(stmt.Lhs) != 2 || len(stmt.Rhs) != 1 || mapIndex(stmt.Rhs[0], info) == nil {
+		return nil
+	}
+	if ident, ok := stmt.Lhs[1].(*ast.Ident); ok && ident.Name == "_" {
+		return nil
+	}
+
+	newStr := source[stmt.Pos():stmt.End()] + ", true"
+	return &Replacement{"MAPDropOk", stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+// Index expression that looks up a key in a map
+func mapIndex(orig ast.Node, info *types.Info) *ast.IndexExpr {
+	expr, ok := orig.(*ast.IndexExpr)
+	if !ok || info == nil {
+		return nil
+	}
+	typ := info.TypeOf(expr.X)
+	if typ == nil {
+		return nil
+	}
+	if _, ok := typ.Underlying().(*types.Map); !ok {
+		return nil
+	}
+	return expr
+}
@@ -2,54 +2,86 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os/exec"
-	"strings"
 )
 
+type ModuleInfo struct {
+	Path      string
+	Dir       string
+	GoVersion string
+}
+
+type PackageError struct {
+	ImportStack []string
+	Pos         string
+	Err         string
+}
+
+// Fields are filled from the output of go list -json
 type PackageInfo struct {
-	Dir          string
-	ImportPath   string
-	GoFiles      []string
-	TestGoFiles  []string
-	ReachDefined bool
+	Dir            string
+	ImportPath     string
+	Name           string
+	GoFiles        []string
+	CgoFiles       []string
+	TestGoFiles    []string
+	XTestGoFiles   []string // Files of the external package_test package
+	EmbedFiles     []string
+	IgnoredGoFiles []string // Files excluded by build constraints
+	Module         *ModuleInfo
+	Error          *PackageError
+	DepsErrors     []*PackageError
+	ReachDefined   bool `json:"-"`
+}
+
+// Directory go commands of this package should run from
+func (pkg *PackageInfo) ModuleRoot() string {
+	if pkg.Module != nil && pkg.Module.Dir != "" {
+		return pkg.Module.Dir
+	}
+	return TMP_ROOT
+}
+
+// Source files that are compiled and mutated
+func (pkg *PackageInfo) SourceFiles() []string {
+	return append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...)
 }
 
 // FIXME: Don't use an external command
 func GetPackageInfo(cfg Config) []PackageInfo {
-	// Output will be a series of lines
-	// each line will have a semicolon separated list of:
-	// Dir ; ImportPath ; GoFiles ; TestGofiles
-	// GoFiles and TestGofiles are Comma Separated Values
-
-	Verbosef("AT (%s) EXEC go list -f {{.Dir}};{{.ImportPath}};{{range .GoFiles}}{{.}},{{end}};{{range .TestGoFiles}}{{.}},{{end}} ./...\n", TMP_ROOT)
-	cmd := exec.Command(
-		"go", "list", "-f",
-		"{{.Dir}};{{.ImportPath}};{{range .GoFiles}}{{.}},{{end}};{{range .TestGoFiles}}{{.}},{{end}}",
-		cfg.Package,
-	)
+	// Output will be a stream of json objects, one per package.
+	// With -e broken packages are reported in their Error fields instead of failing the command
+	Verbosef("AT (%s) EXEC go list -e -json %s\n", TMP_ROOT, cfg.Package)
+	cmd := exec.Command("go", "list", "-e", "-json", cfg.Package)
 	cmd.Dir = TMP_ROOT
 	out, err := cmd.Output()
 
 	if err != nil {
 		panic(err)
 	}
-	lines := strings.Split(string(out), "\n")
-	// Ignore empty last line
-	lines = lines[:len(lines)-1]
 
 	infoList := []PackageInfo{}
-	for _, line := range lines {
-		fields := strings.Split(line, ";")
-		goFiles := strings.Split(fields[2], ",")
-		gotestFiles := strings.Split(fields[3], ",")
-
-		pack := PackageInfo{
-			fields[0],
-			fields[1],
-			// Ignore trailing comma
-			goFiles[:len(goFiles)-1],
-			gotestFiles[:len(gotestFiles)-1],
-			false,
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		pack := PackageInfo{}
+		err := decoder.Decode(&pack)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic(err)
+		}
+
+		// Broken packages can't be tested, skip them
+		if pack.Error != nil {
+			fmt.Printf("?\t%s\t[%s]\n", pack.ImportPath, pack.Error.Err)
+			continue
+		} else if len(pack.DepsErrors) > 0 {
+			fmt.Printf("?\t%s\t[dependency error: %s]\n", pack.ImportPath, pack.DepsErrors[0].Err)
+			continue
 		}
 		infoList = append(infoList, pack)
 	}
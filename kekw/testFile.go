@@ -1,6 +1,9 @@
 package kekw
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 func HasMutation() {
 	a := 0
@@ -74,3 +77,31 @@ func Select2(a <-chan struct{}, b <-chan struct{}) {
 	case <-b:
 	}
 }
+
+func Select3(a <-chan int) {
+	total := 0
+	select {
+	case v := <-a:
+		total += v
+		fmt.Println(total)
+	case <-time.After(time.Second):
+	}
+}
+
+func Fallthrough(n int) {
+	switch n {
+	case 0:
+		fmt.Println(0)
+		fallthrough
+	default:
+	}
+}
+
+func TerminatingSwitch(n int) string {
+	switch n {
+	case 0:
+		return "zero"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,173 @@
+// Literal (LIT) mutators, they replace constant values written in the code
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+type LITEmptyString struct{}
+
+func (LITEmptyString) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	lit := stringLiteral(orig, path)
+	if lit == nil {
+		return nil
+	}
+	if value, err := strconv.Unquote(lit.Value); err != nil || value == "" || returnedAsZero(lit, `""`, path, info) {
+		return nil
+	}
+	return exprReplacement("LITEmptyString", source, path, lit, `""`)
+}
+
+type LITMutatedString struct{}
+
+func (LITMutatedString) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	lit := stringLiteral(orig, path)
+	if lit == nil {
+		return nil
+	}
+	if value, err := strconv.Unquote(lit.Value); err != nil || value == "MUTATED" {
+		return nil
+	}
+	return exprReplacement("LITMutatedString", source, path, lit, `"MUTATED"`)
+}
+
+// String literals, except struct tags
+func stringLiteral(orig ast.Node, path []ast.Node) *ast.BasicLit {
+	lit, ok := orig.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING || len(path) < 2 {
+		return nil
+	}
+	if _, ok := path[len(path)-2].(*ast.Field); ok {
+		return nil
+	}
+	return lit
+}
+
+type LITFlipBool struct{}
+
+// true => false, false => true
+func (LITFlipBool) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	ident, ok := orig.(*ast.Ident)
+	if !ok || info == nil {
+		return nil
+	}
+
+	flipped := map[string]string{"true": "false", "false": "true"}[ident.Name]
+	if flipped == "" || info.Uses[ident] != types.Universe.Lookup(ident.Name) || returnedAsZero(ident, flipped, path, info) {
+		return nil
+	}
+	return exprReplacement("LITFlipBool", source, path, ident, flipped)
+}
+
+type LITNilToZero struct{}
+
+// nil => []T{}, map[K]V{} or &T{} for pointers to structs
+func (LITNilToZero) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	ident, ok := orig.(*ast.Ident)
+	if !ok || info == nil || !info.Types[ident].IsNil() {
+		return nil
+	}
+	typ := nilTarget(ident, path, info)
+	if typ == nil {
+		return nil
+	}
+
+	file := path[0].(*ast.File)
+	value := ""
+	switch t := typ.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		if name := typeString(typ, file, info); name != "" {
+			value = name + "{}"
+		}
+	case *types.Pointer:
+		if _, ok := t.Elem().Underlying().(*types.Struct); !ok {
+			return nil
+		}
+		if name := typeString(t.Elem(), file, info); name != "" {
+			value = "&" + name + "{}"
+		}
+	}
+	if value == "" {
+		return nil
+	}
+	return exprReplacement("LITNilToZero", source, path, ident, value)
+}
+
+// Type nil takes where it is assigned, returned or passed as an argument.
+// go/types records nil as untyped, so the type comes from where it is used
+func nilTarget(ident *ast.Ident, path []ast.Node, info *types.Info) types.Type {
+	if len(path) < 2 {
+		return nil
+	}
+
+	switch parent := path[len(path)-2].(type) {
+	case *ast.AssignStmt:
+		if parent.Tok != token.ASSIGN || len(parent.Lhs) != len(parent.Rhs) {
+			return nil
+		}
+		for i, rhs := range parent.Rhs {
+			if rhs == ident {
+				return info.TypeOf(parent.Lhs[i])
+			}
+		}
+	case *ast.ValueSpec:
+		if parent.Type != nil {
+			return info.TypeOf(parent.Type)
+		}
+	case *ast.ReturnStmt:
+		_, typ := returnedValue(ident, path, info)
+		return typ
+	case *ast.CallExpr:
+		sig, ok := info.TypeOf(parent.Fun).(*types.Signature)
+		if !ok {
+			return nil
+		}
+		for i, arg := range parent.Args {
+			// Variadic arguments are left out
+			if arg == ident && i < sig.Params().Len() && !(sig.Variadic() && i >= sig.Params().Len()-1) {
+				return sig.Params().At(i).Type()
+			}
+		}
+	}
+	return nil
+}
+
+type LITZero struct{}
+
+// Numeric literals become 0, except when they are divisors or returned
+func (LITZero) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	lit, ok := orig.(*ast.BasicLit)
+	if !ok || (lit.Kind != token.INT && lit.Kind != token.FLOAT) || len(path) < 2 || info == nil {
+		return nil
+	}
+	if tv, ok := info.Types[lit]; lit.Value == "0" || (ok && tv.Value != nil && constant.Sign(tv.Value) == 0) {
+		return nil
+	}
+	switch parent := path[len(path)-2].(type) {
+	case *ast.BinaryExpr:
+		if parent.Y == lit && (parent.Op == token.QUO || parent.Op == token.REM) {
+			return nil
+		}
+	case *ast.AssignStmt:
+		if parent.Tok == token.QUO_ASSIGN || parent.Tok == token.REM_ASSIGN {
+			return nil
+		}
+	}
+	if returnedAsZero(lit, "0", path, info) {
+		return nil
+	}
+	return exprReplacement("LITZero", source, path, lit, "0")
+}
+
+// Whether RETZeroValue already replaces the returned literal by the same value
+func returnedAsZero(lit ast.Expr, value string, path []ast.Node, info *types.Info) bool {
+	expr, typ := returnedValue(lit, path, info)
+	if expr == nil || isError(typ) || isCollection(typ) {
+		return false
+	}
+	return zeroValue(typ, path[0].(*ast.File), info) == value
+}
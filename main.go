@@ -2,20 +2,23 @@ package main
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -30,7 +33,7 @@ type Replacement struct {
 }
 
 type Mutator interface {
-	replacement(string, ast.Node, []ast.Node) *Replacement
+	replacement(string, ast.Node, []ast.Node, *types.Info) *Replacement
 }
 
 type ChangeMode int
@@ -41,11 +44,30 @@ const (
 	SC_DELETE
 )
 
+type MutationStatus int
+
+const (
+	MS_NOT_COVERED MutationStatus = iota // No test reaches the mutation
+	MS_SKIPPED                           // Reachable but not selected to run
+	MS_SURVIVED
+	MS_KILLED
+	MS_TIMED_OUT
+	MS_COMPILE_ERROR
+	MS_RUNTIME_ERROR // The tests crashed with a fatal error of the runtime, like concurrent map writes
+)
+
+var STATUS_NAMES = []string{"NotCovered", "Skipped", "Survived", "Killed", "TimedOut", "CompileError", "RuntimeError"}
+
+func (s MutationStatus) String() string {
+	return STATUS_NAMES[s]
+}
+
 type Mutation struct {
+	Id     int // Value of EnabledMutation that turns this mutation on
 	File   *FileInfo
 	Change *Replacement
 	Pos    token.Pos
-	Alive  bool
+	Status MutationStatus
 }
 
 type FileInfo struct {
@@ -55,11 +77,22 @@ type FileInfo struct {
 	Package *PackageInfo
 	AST     *ast.File
 	Imports map[string]bool
+	// Test file of the external package_test package
+	External bool
+	// Types of the file, nil for test files
+	Info *types.Info
 	// Mutations per block
 	Mutations map[token.Pos][]*Mutation
+	Blocks    map[token.Pos]*Block
 	Changes   map[token.Pos]*SourceChange
 }
 
+// Block that scopes mutations, as found by getParent
+type Block struct {
+	Node ast.Node
+	At   token.Pos // Where its first statement goes
+}
+
 // Even parsing with comments still can mess compiler directives
 // This is a way to change the ast without moving anything else
 type SourceChange struct {
@@ -84,6 +117,11 @@ var (
 		UOIIncrementer{},
 		UOIDecrementer{},
 
+		// Unary operators: Insert or remove
+		UOIRemoveNot{},
+		UOIRemoveNeg{},
+		UOIInsertNot{},
+
 		// Math: Replace operation
 		// AORModToAdd{},
 		// AORModToSub{},
@@ -93,16 +131,135 @@ var (
 		AORPlusToMod{},
 		AORMinusToDiv{},
 
+		// Strings: Empty one side of a concatenation
+		STRConcatLeftEmpty{},
+		STRConcatRightEmpty{},
+
 		// Logic: Change connector
 		LCRAndToOr{},
 		LCROrToAnd{},
 
+		// Bitwise and shifts
+		OBRAndToOr{},
+		OBROrToAnd{},
+		OBRXorToOr{},
+		OBRXorToAnd{},
+		OBRAndNotToAnd{},
+		OBRShlToShr{},
+		OBRShrToShl{},
+		OBRRemoveComplement{},
+
 		// Comparison
 		RORNeqToLeq{},
 		RORLeqToNeq{},
-		RORNeqToLeq{},
-		ROREqToNeq{},
+		RORNeqToGeq{},
 		RORLtToNeq{},
+
+		// Comparison: Conditional boundary
+		RORLtToLeq{},
+		RORLeqToLt{},
+		RORGtToGeq{},
+		RORGeqToGt{},
+
+		// Comparison: Negation
+		ROREqToNeq{},
+		RORNeqToEq{},
+		RORLtToGeq{},
+		RORGeqToLt{},
+		RORGtToLeq{},
+		RORLeqToGt{},
+
+		// Comparison: Replace the whole condition
+		RORConditionTrue{},
+		RORConditionFalse{},
+
+		// Literals
+		LITEmptyString{},
+		LITMutatedString{},
+		LITFlipBool{},
+		LITNilToZero{},
+		LITZero{},
+
+		// Return values
+		RETZeroValue{},
+		RETNegateBool{},
+		RETNilError{},
+		RETNewError{},
+		RETNilCollection{},
+
+		// Error handling
+		ERRRemoveCheck{},
+		ERRInvertCheck{},
+		ERRSwallow{},
+
+		// Control flow
+		CFRemoveElse{},
+		CFIfTrue{},
+		CFIfFalse{},
+		CFBreakToContinue{},
+		CFContinueToBreak{},
+		CFLoopZero{},
+		CFLoopOnce{},
+
+		// Switch and select clauses
+		SWDeleteCaseBody{},
+		SWEmptyDefault{},
+		SWRemoveFallthrough{},
+		SELDropCase{},
+
+		// Assignments and increments
+		ASRAddToSub{},
+		ASRSubToAdd{},
+		ASRMulToQuo{},
+		ASRQuoToMul{},
+		ASRRemToMul{},
+		ASRAndToOr{},
+		ASROrToAnd{},
+		ASRXorToOr{},
+		ASRAndNotToAnd{},
+		ASRShlToShr{},
+		ASRShrToShl{},
+		ASRToAssign{},
+		IDDIncToDec{},
+		IDDDecToInc{},
+
+		// Concurrency
+		CONRemoveLock{},
+		CONRemoveClose{},
+		CONGoToSync{},
+		CONRemoveDone{},
+		CONRemoveAdd{},
+		CONBufferedToUnbuffered{},
+		CONUnbufferedToBuffered{},
+
+		// Function calls
+		CALLRemoveVoid{},
+		CALLSwapArgs{},
+		CALLZeroResult{},
+		CALLAppendToSlice{},
+
+		// Slices and maps
+		SLCLowInc{},
+		SLCLowDec{},
+		SLCHighInc{},
+		SLCHighDec{},
+		MAPZeroRead{},
+		MAPDropOk{},
+
+		// Contexts and durations
+		CTXBackground{},
+		CTXRemoveCancel{},
+		CTXRemoveErrCheck{},
+		TIMEMultiply{},
+		TIMEShorten{},
+
+		// Statement deletion
+		SDLExpression{},
+		SDLAssignment{},
+		SDLDefer{},
+		SDLGo{},
+		SDLSend{},
+		SDLIncDec{},
 	}
 	TMP_ROOT string
 	FLAGS    = map[string]string{
@@ -168,12 +325,42 @@ func MutationString(source string, stmt ast.Node, og ast.Node, new ast.Node) (st
 	return source[stmt.Pos():stmt.End()], writer.String()
 }
 
-func (UOIIncrementer) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	node, ok := orig.(*ast.BasicLit)
-	if !ok {
-		return nil
+func (UOIIncrementer) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return incrementReplacement("UOIIncrementer", source, path, info, orig, token.ADD)
+}
+
+type UOIDecrementer struct{}
+
+func (UOIDecrementer) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return incrementReplacement("UOIDecrementer", source, path, info, orig, token.SUB)
+}
+
+// Adds or subtracts one from numeric literals and variables: 2 => (2 + 1), x => (x + 1)
+func incrementReplacement(issuer string, source string, path []ast.Node, info *types.Info, orig ast.Node, op token.Token) *Replacement {
+	// Slice bounds are left to the SLC mutators
+	if len(path) > 1 {
+		if slice, ok := path[len(path)-2].(*ast.SliceExpr); ok && (slice.Low == orig || slice.High == orig) {
+			return nil
+		}
 	}
-	if node.Kind != token.FLOAT && node.Kind != token.INT {
+
+	var new ast.Expr
+	switch node := orig.(type) {
+	case *ast.BasicLit:
+		if node.Kind != token.FLOAT && node.Kind != token.INT {
+			return nil
+		}
+		// Constant indices and sizes can't be negative: s[0] => s[(0 - 1)]
+		if op == token.SUB && constant.Sign(constant.MakeFromLiteral(node.Value, node.Kind, 0)) == 0 && isIndexOrSize(node, path, info) {
+			return nil
+		}
+		new = &ast.ParenExpr{X: &ast.BinaryExpr{X: node, OpPos: token.NoPos, Op: op, Y: &ONE}}
+	case *ast.Ident:
+		if !isRead(node, path, info) || !isNumeric(info.TypeOf(node)) {
+			return nil
+		}
+		new = &ast.ParenExpr{X: &ast.BinaryExpr{X: node, OpPos: token.NoPos, Op: op, Y: &ONE}}
+	default:
 		return nil
 	}
 
@@ -182,35 +369,95 @@ func (UOIIncrementer) replacement(source string, orig ast.Node, path []ast.Node)
 		return nil
 	}
 
-	new := &ast.BinaryExpr{X: node, OpPos: token.NoPos, Op: token.ADD, Y: &ONE}
 	oldStr, newStr := MutationString(source, stmt, orig, new)
-	return &Replacement{"UOIIncrementer", orig, stmt, newStr, oldStr}
+	return &Replacement{issuer, orig, stmt, newStr, oldStr}
 }
 
-type UOIDecrementer struct{}
+// Whether the expression is an index or the length or capacity given to make
+func isIndexOrSize(expr ast.Expr, path []ast.Node, info *types.Info) bool {
+	switch parent := path[len(path)-2].(type) {
+	case *ast.IndexExpr:
+		return parent.Index == expr
+	case *ast.CallExpr:
+		if !isBuiltin(parent.Fun, "make", info) {
+			return false
+		}
+		for i, arg := range parent.Args {
+			if i > 0 && arg == expr {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-func (UOIDecrementer) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	node, ok := orig.(*ast.BasicLit)
-	if !ok {
+type UOIRemoveNot struct{}
+
+func (UOIRemoveNot) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return unaryRemoval("UOIRemoveNot", source, path, orig, token.NOT)
+}
+
+type UOIRemoveNeg struct{}
+
+func (UOIRemoveNeg) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return unaryRemoval("UOIRemoveNeg", source, path, orig, token.SUB)
+}
+
+func unaryRemoval(issuer string, source string, path []ast.Node, orig ast.Node, op token.Token) *Replacement {
+	node, ok := orig.(*ast.UnaryExpr)
+	if !ok || node.Op != op {
+		return nil
+	}
+
+	stmt := RootStmt(path)
+	if stmt == nil {
+		return nil
+	}
+
+	oldStr, newStr := MutationString(source, stmt, orig, node.X)
+	return &Replacement{issuer, orig, stmt, newStr, oldStr}
+}
+
+type UOIInsertNot struct{}
+
+// Negates boolean variables, fields, calls and index expressions: ok => !(ok)
+func (UOIInsertNot) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	switch orig.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr:
+	default:
+		return nil
+	}
+	expr := orig.(ast.Expr)
+	if !isRead(expr, path, info) {
+		return nil
+	}
+	if basic, ok := info.TypeOf(expr).Underlying().(*types.Basic); !ok || basic.Info()&types.IsBoolean == 0 {
 		return nil
 	}
-	if node.Kind != token.FLOAT && node.Kind != token.INT {
+	// !!x would only give x back
+	if parent, ok := path[len(path)-2].(*ast.UnaryExpr); ok && parent.Op == token.NOT {
 		return nil
 	}
+	// Returned booleans are negated by RETNegateBool
+	if ret, typ := returnedValue(expr, path, info); ret != nil {
+		if basic, ok := typ.Underlying().(*types.Basic); ok && basic.Info()&types.IsBoolean != 0 {
+			return nil
+		}
+	}
 
 	stmt := RootStmt(path)
 	if stmt == nil {
 		return nil
 	}
 
-	new := &ast.BinaryExpr{X: node, OpPos: token.NoPos, Op: token.SUB, Y: &ONE}
+	new := &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: expr}}
 	oldStr, newStr := MutationString(source, stmt, orig, new)
-	return &Replacement{"UOIDecrementer", orig, stmt, newStr, oldStr}
+	return &Replacement{"UOIInsertNot", orig, stmt, newStr, oldStr}
 }
 
-func operatorReplacement(issuer string, source string, path []ast.Node, orig ast.Node, op token.Token, newOp token.Token) *Replacement {
+func operatorReplacement(issuer string, source string, path []ast.Node, info *types.Info, orig ast.Node, op token.Token, newOp token.Token) *Replacement {
 	node, ok := orig.(*ast.BinaryExpr)
-	if !ok || node.Op != op {
+	if !ok || node.Op != op || !validOperator(info, node, newOp) {
 		return nil
 	}
 
@@ -226,120 +473,331 @@ func operatorReplacement(issuer string, source string, path []ast.Node, orig ast
 
 type AORMinusToDiv struct{}
 
-func (AORMinusToDiv) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORMinusToDiv", source, path, orig, token.SUB, token.QUO)
+func (AORMinusToDiv) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORMinusToDiv", source, path, info, orig, token.SUB, token.QUO)
 }
 
 type AORModToAdd struct{}
 
-func (AORModToAdd) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORModToAdd", source, path, orig, token.REM, token.ADD)
+func (AORModToAdd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORModToAdd", source, path, info, orig, token.REM, token.ADD)
 }
 
 type AORModToSub struct{}
 
-func (AORModToSub) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORModToSub", source, path, orig, token.REM, token.SUB)
+func (AORModToSub) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORModToSub", source, path, info, orig, token.REM, token.SUB)
 }
 
 type AORMinusToPlus struct{}
 
-func (AORMinusToPlus) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORMinusToPlus", source, path, orig, token.SUB, token.ADD)
+func (AORMinusToPlus) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORMinusToPlus", source, path, info, orig, token.SUB, token.ADD)
 }
 
 type AORPlusToMod struct{}
 
-func (AORPlusToMod) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORPlusToMod", source, path, orig, token.ADD, token.REM)
+func (AORPlusToMod) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORPlusToMod", source, path, info, orig, token.ADD, token.REM)
 }
 
 type AORMinusToMult struct{}
 
-func (AORMinusToMult) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORMinusToMult", source, path, orig, token.SUB, token.MUL)
+func (AORMinusToMult) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORMinusToMult", source, path, info, orig, token.SUB, token.MUL)
 }
 
 type AORDivToMult struct{}
 
-func (AORDivToMult) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORDivToMult", source, path, orig, token.QUO, token.MUL)
+func (AORDivToMult) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORDivToMult", source, path, info, orig, token.QUO, token.MUL)
 }
 
 type AORMultToPlus struct{}
 
-func (AORMultToPlus) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORMultToPlus", source, path, orig, token.MUL, token.ADD)
+func (AORMultToPlus) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORMultToPlus", source, path, info, orig, token.MUL, token.ADD)
 }
 
 type AORMultToSub struct{}
 
-func (AORMultToSub) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORMultToSub", source, path, orig, token.MUL, token.SUB)
+func (AORMultToSub) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORMultToSub", source, path, info, orig, token.MUL, token.SUB)
 }
 
 type AORDivToMod struct{}
 
-func (AORDivToMod) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORDivToMod", source, path, orig, token.QUO, token.REM)
+func (AORDivToMod) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORDivToMod", source, path, info, orig, token.QUO, token.REM)
 }
 
 type AORPlusToDiv struct{}
 
-func (AORPlusToDiv) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("AORPlusToDiv", source, path, orig, token.ADD, token.QUO)
+func (AORPlusToDiv) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("AORPlusToDiv", source, path, info, orig, token.ADD, token.QUO)
+}
+
+// Replaces one side of a string concatenation with an empty string.
+// The side is sliced to [:0] instead of removed, so the variables it uses are still used
+func concatReplacement(issuer string, source string, path []ast.Node, info *types.Info, orig ast.Node, left bool) *Replacement {
+	node, ok := orig.(*ast.BinaryExpr)
+	if !ok || node.Op != token.ADD || info == nil {
+		return nil
+	}
+	// Slicing a constant string doesn't give a constant
+	tv := info.Types[node]
+	if tv.Type == nil || tv.Value != nil {
+		return nil
+	}
+	if basic, ok := tv.Type.Underlying().(*types.Basic); !ok || basic.Info()&types.IsString == 0 {
+		return nil
+	}
+
+	stmt := RootStmt(path)
+	if stmt == nil {
+		return nil
+	}
+
+	empty := func(expr ast.Expr) ast.Expr {
+		if _, ok := expr.(*ast.BinaryExpr); ok {
+			expr = &ast.ParenExpr{X: expr}
+		}
+		return &ast.SliceExpr{X: expr, High: &ast.BasicLit{Kind: token.INT, Value: "0"}}
+	}
+	new := &ast.BinaryExpr{X: node.X, OpPos: token.NoPos, Op: token.ADD, Y: empty(node.Y)}
+	if left {
+		new = &ast.BinaryExpr{X: empty(node.X), OpPos: token.NoPos, Op: token.ADD, Y: node.Y}
+	}
+	oldStr, newStr := MutationString(source, stmt, orig, new)
+	return &Replacement{issuer, orig, stmt, newStr, oldStr}
+}
+
+type STRConcatLeftEmpty struct{}
+
+func (STRConcatLeftEmpty) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return concatReplacement("STRConcatLeftEmpty", source, path, info, orig, true)
+}
+
+type STRConcatRightEmpty struct{}
+
+func (STRConcatRightEmpty) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return concatReplacement("STRConcatRightEmpty", source, path, info, orig, false)
 }
 
 type LCRAndToOr struct{}
 
-func (LCRAndToOr) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("LCRAndToOr", source, path, orig, token.LAND, token.LOR)
+func (LCRAndToOr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("LCRAndToOr", source, path, info, orig, token.LAND, token.LOR)
 }
 
 type LCROrToAnd struct{}
 
-func (LCROrToAnd) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("LCROrToAnd", source, path, orig, token.LOR, token.LAND)
+func (LCROrToAnd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("LCROrToAnd", source, path, info, orig, token.LOR, token.LAND)
+}
+
+type OBRAndToOr struct{}
+
+func (OBRAndToOr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBRAndToOr", source, path, info, orig, token.AND, token.OR)
+}
+
+type OBROrToAnd struct{}
+
+func (OBROrToAnd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBROrToAnd", source, path, info, orig, token.OR, token.AND)
+}
+
+type OBRXorToOr struct{}
+
+func (OBRXorToOr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBRXorToOr", source, path, info, orig, token.XOR, token.OR)
+}
+
+type OBRXorToAnd struct{}
+
+func (OBRXorToAnd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBRXorToAnd", source, path, info, orig, token.XOR, token.AND)
+}
+
+type OBRAndNotToAnd struct{}
+
+func (OBRAndNotToAnd) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBRAndNotToAnd", source, path, info, orig, token.AND_NOT, token.AND)
+}
+
+type OBRShlToShr struct{}
+
+func (OBRShlToShr) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBRShlToShr", source, path, info, orig, token.SHL, token.SHR)
+}
+
+type OBRShrToShl struct{}
+
+func (OBRShrToShl) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("OBRShrToShl", source, path, info, orig, token.SHR, token.SHL)
+}
+
+type OBRRemoveComplement struct{}
+
+// ^x => x
+func (OBRRemoveComplement) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	node, ok := orig.(*ast.UnaryExpr)
+	if !ok || node.Op != token.XOR || info == nil || info.TypeOf(node) == nil {
+		return nil
+	}
+	if basic, ok := info.TypeOf(node).Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+		return nil
+	}
+
+	stmt := RootStmt(path)
+	if stmt == nil {
+		return nil
+	}
+
+	oldStr, newStr := MutationString(source, stmt, orig, node.X)
+	return &Replacement{"OBRRemoveComplement", orig, stmt, newStr, oldStr}
 }
 
 type RORNeqToLeq struct{}
 
-func (RORNeqToLeq) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("RORNeqToLeq", source, path, orig, token.NEQ, token.LEQ)
+func (RORNeqToLeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORNeqToLeq", source, path, info, orig, token.NEQ, token.LEQ)
 }
 
 type RORLeqToNeq struct{}
 
-func (RORLeqToNeq) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("RORLeqToNeq", source, path, orig, token.LEQ, token.NEQ)
+func (RORLeqToNeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORLeqToNeq", source, path, info, orig, token.LEQ, token.NEQ)
 }
 
 type RORNeqToGeq struct{}
 
-func (RORNeqToGeq) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("RORNeqToGeq", source, path, orig, token.NEQ, token.GEQ)
+func (RORNeqToGeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORNeqToGeq", source, path, info, orig, token.NEQ, token.GEQ)
 }
 
 type ROREqToNeq struct{}
 
-func (ROREqToNeq) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("ROREqToNeq", source, path, orig, token.EQL, token.NEQ)
+func (ROREqToNeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("ROREqToNeq", source, path, info, orig, token.EQL, token.NEQ)
 }
 
 type RORLtToNeq struct{}
 
-func (RORLtToNeq) replacement(source string, orig ast.Node, path []ast.Node) *Replacement {
-	return operatorReplacement("RORLtToNeq", source, path, orig, token.LSS, token.NEQ)
+func (RORLtToNeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORLtToNeq", source, path, info, orig, token.LSS, token.NEQ)
+}
+
+type RORLtToLeq struct{}
+
+func (RORLtToLeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORLtToLeq", source, path, info, orig, token.LSS, token.LEQ)
+}
+
+type RORLeqToLt struct{}
+
+func (RORLeqToLt) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORLeqToLt", source, path, info, orig, token.LEQ, token.LSS)
+}
+
+type RORGtToGeq struct{}
+
+func (RORGtToGeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORGtToGeq", source, path, info, orig, token.GTR, token.GEQ)
+}
+
+type RORGeqToGt struct{}
+
+func (RORGeqToGt) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORGeqToGt", source, path, info, orig, token.GEQ, token.GTR)
+}
+
+type RORNeqToEq struct{}
+
+func (RORNeqToEq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	// Error checks are left to ERRInvertCheck
+	if isErrorCheckCond(orig, path, info) {
+		return nil
+	}
+	return operatorReplacement("RORNeqToEq", source, path, info, orig, token.NEQ, token.EQL)
+}
+
+type RORLtToGeq struct{}
+
+func (RORLtToGeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORLtToGeq", source, path, info, orig, token.LSS, token.GEQ)
+}
+
+type RORGeqToLt struct{}
+
+func (RORGeqToLt) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORGeqToLt", source, path, info, orig, token.GEQ, token.LSS)
+}
+
+type RORGtToLeq struct{}
+
+func (RORGtToLeq) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORGtToLeq", source, path, info, orig, token.GTR, token.LEQ)
+}
+
+type RORLeqToGt struct{}
+
+func (RORLeqToGt) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return operatorReplacement("RORLeqToGt", source, path, info, orig, token.LEQ, token.GTR)
+}
+
+// Replaces a comparison with a constant, the comparison is kept short-circuited
+// so the variables it uses are still used: true || (a < b)
+func conditionReplacement(issuer string, source string, path []ast.Node, orig ast.Node, value string) *Replacement {
+	node, ok := orig.(*ast.BinaryExpr)
+	if !ok {
+		return nil
+	}
+	switch node.Op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+	default:
+		return nil
+	}
+
+	return constantCondition(issuer, source, path, node, value)
+}
+
+func constantCondition(issuer string, source string, path []ast.Node, cond ast.Expr, value string) *Replacement {
+	stmt := RootStmt(path)
+	if stmt == nil {
+		return nil
+	}
+
+	op := token.LOR
+	if value == "false" {
+		op = token.LAND
+	}
+	new := &ast.BinaryExpr{X: ast.NewIdent(value), OpPos: token.NoPos, Op: op, Y: &ast.ParenExpr{X: cond}}
+	oldStr, newStr := MutationString(source, stmt, cond, new)
+	return &Replacement{issuer, cond, stmt, newStr, oldStr}
+}
+
+type RORConditionTrue struct{}
+
+func (RORConditionTrue) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return conditionReplacement("RORConditionTrue", source, path, orig, "true")
+}
+
+type RORConditionFalse struct{}
+
+func (RORConditionFalse) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	return conditionReplacement("RORConditionFalse", source, path, orig, "false")
 }
 
 // func schemata[T any](toggle string, origEx T, replaceEx ...T) T {
 // 	return origEx
 // }
 
-func mutations(source string, node ast.Node, path []ast.Node, mutators []Mutator) []*Replacement {
+func mutations(source string, node ast.Node, path []ast.Node, info *types.Info, mutators []Mutator) []*Replacement {
 	changes := []*Replacement{}
 	for _, mut := range mutators {
-		change := mut.replacement(source, node, path)
+		change := mut.replacement(source, node, path, info)
 		if change != nil {
 			changes = append(changes, change)
 		}
@@ -370,6 +828,7 @@ func (ft *FileTable) NewFileInfo(path string, pack *PackageInfo) *FileInfo {
 		Changes:   make(map[token.Pos]*SourceChange),
 		Imports:   make(map[string]bool),
 		Mutations: make(map[token.Pos][]*Mutation),
+		Blocks:    make(map[token.Pos]*Block),
 	}
 
 	file.Source, err = os.ReadFile(path)
@@ -386,22 +845,35 @@ func (ft *FileTable) NewFileInfo(path string, pack *PackageInfo) *FileInfo {
 		file.Imports[spec.Path.Value] = true
 	}
 
+	if ft.Packages == nil {
+		ft.Packages = make(map[string]*PackageInfo)
+	}
+	ft.Packages[pack.ImportPath] = pack
+
 	file.Id = len(ft.Files)
 	ft.Files = append(ft.Files, &file)
 	return &file
 }
 
-// Returns a map of (blockLocation => testLocations[])
+// Returns a map of (blockLocation => tests[])
 // blockLocation is the location of the parentBlock of one or more mutations
-func ParseCoverage(source string) map[NodeIdentifier][]NodeIdentifier {
-	var currentTest NodeIdentifier
+func ParseCoverage(source string) map[NodeIdentifier][]TestIdentifier {
+	var currentTest TestIdentifier
 
-	testsPerBlock := make(map[NodeIdentifier][]NodeIdentifier)
+	testsPerBlock := make(map[NodeIdentifier][]TestIdentifier)
+	seen := make(map[NodeIdentifier]map[TestIdentifier]bool)
 	for _, line := range strings.Split(source, "\n") {
 		info := strings.Split(line, " ")
-		// info[0] (Tag) = T or R
-		// info[1] (Node Identifier) = fileId:NodePos
-		if len(info) != 2 {
+		// info[0] (Tag) = T, E or R
+		// T and E: info[1] = ImportPath, info[2] = Test name (a subtest Started or Ended)
+		// R: info[1] (Node Identifier) = fileId:NodePos
+		if len(info) == 3 && info[0] == "T" {
+			currentTest = TestIdentifier{info[1], info[2]}
+			continue
+		} else if len(info) == 3 && info[0] == "E" {
+			currentTest = TestIdentifier{info[1], info[2]}.Parent()
+			continue
+		} else if len(info) != 2 || info[0] != "R" {
 			continue
 		}
 
@@ -414,9 +886,12 @@ func ParseCoverage(source string) map[NodeIdentifier][]NodeIdentifier {
 		nodePos, _ := strconv.ParseInt(ident[1], 10, 64)
 
 		nodeIdentifier := NodeIdentifier{int(fileId), int(nodePos)}
-		if info[0] == "T" {
-			currentTest = nodeIdentifier
-		} else if info[0] == "R" {
+		if seen[nodeIdentifier] == nil {
+			seen[nodeIdentifier] = make(map[TestIdentifier]bool)
+		}
+		// A parent test can report the same block again after each of its subtests
+		if !seen[nodeIdentifier][currentTest] {
+			seen[nodeIdentifier][currentTest] = true
 			testsPerBlock[nodeIdentifier] = append(testsPerBlock[nodeIdentifier], currentTest)
 		}
 	}
@@ -428,10 +903,34 @@ type NodeIdentifier struct {
 	NodePos int
 }
 
+// A test or subtest, named as reported by t.Name()
+type TestIdentifier struct {
+	Package string
+	Name    string
+}
+
+func (t TestIdentifier) Parent() TestIdentifier {
+	if i := strings.LastIndex(t.Name, "/"); i >= 0 {
+		return TestIdentifier{t.Package, t.Name[:i]}
+	}
+	return t
+}
+
+// Anchored -run pattern matching only this test, e.g: ^TestX$/^case$
+func (t TestIdentifier) RunPattern() string {
+	levels := strings.Split(t.Name, "/")
+	for i, level := range levels {
+		levels[i] = "^" + regexp.QuoteMeta(level) + "$"
+	}
+	return strings.Join(levels, "/")
+}
+
 func GolangMut(cfg Config) {
 	var coverageData string = ""
 	// Get all packages at the cfg.Package path
 	packages := GetPackageInfo(cfg)
+	// Make sure the tests pass and measure them, before any change to the code
+	baseline := RunBaseline(cfg, packages)
 
 	// Use a given coverage file
 	if cfg.CoverageFile != "" {
@@ -447,14 +946,18 @@ func GolangMut(cfg Config) {
 	}
 
 	ft := FileTable{}
-	// If not provided with coverage file, ensure one was generated
-	if coverageData == "" {
+	var testsPerBlock map[NodeIdentifier][]TestIdentifier
+	if coverageData == "" && cfg.GoCover {
+		// Reachability from go test -cover, the sources are left untouched
+		testsPerBlock = ft.CollectCoverage(packages)
+	} else if coverageData == "" {
+		// If not provided with coverage file, ensure one was generated
 		// For each package:
 		// - Add files to FileTable
 		// - Instrument files
 		// - Run go test
-		for _, pkg := range packages {
-			ft.InstrumentPackage(&pkg)
+		for i := range packages {
+			ft.InstrumentPackage(&packages[i])
 		}
 
 		reach, err := os.ReadFile(filepath.Join(TMP_ROOT, "reach.log"))
@@ -465,7 +968,9 @@ func GolangMut(cfg Config) {
 	}
 
 	// For each parent block of a mutation we have the tests that reached it
-	testsPerBlock := ParseCoverage(coverageData)
+	if testsPerBlock == nil {
+		testsPerBlock = ParseCoverage(coverageData)
+	}
 
 	// Get all reachable mutations
 	reachableMutations := []*Mutation{}
@@ -473,6 +978,9 @@ func GolangMut(cfg Config) {
 		file := ft.Files[block.FileId]
 		reachableMutations = append(reachableMutations, file.Mutations[token.Pos(block.NodePos)]...)
 	}
+	for _, mutation := range reachableMutations {
+		mutation.Status = MS_SKIPPED
+	}
 
 	// Select mutations a random fixed number of mutations
 	// https://doi.org/10.1109/ISSRE.2015.7381815
@@ -495,8 +1003,18 @@ func GolangMut(cfg Config) {
 		}
 	}
 
+	WriteAndExecute(&Execution{cfg, &ft, testsPerBlock, baseline}, selectedMutations)
+	// The score is an estimate from the sample, mutations no test reaches are reported apart
+	fmt.Println("")
+	if score, ok := MutationScore(selectedMutations); ok {
+		color.Yellow("MUTATION SCORE: %.2f%%", score)
+	} else {
+		color.Yellow("MUTATION SCORE: N/A (no valid mutations)")
+	}
+	if coverage, ok := MutationCoverage(allMutations, reachableMutations); ok {
+		color.Yellow("MUTATION COVERAGE: %.2f%% (%d of %d reached by tests)", coverage, len(reachableMutations), len(allMutations))
+	}
 	GenReport(allMutations, selectedMutations, reachableMutations)
-	WriteAndExecute(&ft, testsPerBlock, selectedMutations)
 	// Group selected mutations by statement
 	// mutationsPerStatement := map[*ast.Stmt][]*Mutation{}
 }
@@ -510,20 +1028,8 @@ func (m *Mutation) Identifier() NodeIdentifier {
 	return NodeIdentifier{m.File.Id, int(m.Pos)}
 }
 
-func GetTestName(ft *FileTable, test NodeIdentifier) string {
-	name := []byte{}
-	index := test.NodePos + 5
-	source := ft.Files[test.FileId].Source
-	current := source[index]
-	for token.IsIdentifier(string(current)) {
-		name = append(name, current)
-		index = index + 1
-		current = source[index]
-	}
-	return string(name)
-}
-
-func (m *Mutation) Write() {
+// Source of the file with only this mutation applied
+func (m *Mutation) Source() []byte {
 	file := m.File
 
 	writer := bytes.Buffer{}
@@ -531,56 +1037,65 @@ func (m *Mutation) Write() {
 	writer.WriteString(m.Change.NewStr)
 	writer.WriteString(string(file.Source[m.Change.Stmt.End():len(file.Source)]))
 
-	os.WriteFile(file.Path, writer.Bytes(), 0777)
+	return writer.Bytes()
 }
 
-func WriteAndExecute(ft *FileTable, testsPerBlock map[NodeIdentifier][]NodeIdentifier, selected []*Mutation) {
+func WriteAndExecute(ex *Execution, selected []*Mutation) {
 	// Undo the instrumentation
-	for _, file := range ft.Files {
-		file.Reset()
-	}
-
-	for _, mutation := range selected {
-		mutation.Alive = true
-		mutation.Write()
-		tests := testsPerBlock[mutation.Identifier()]
-		// fmt.Println(len(tests))
-		for _, test := range tests {
-			testName := GetTestName(ft, test)
-			file := ft.Files[test.FileId]
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-
-			fmt.Println("go test " + file.Package.ImportPath + " -run " + testName)
-			err := exec.CommandContext(ctx, "go", "test", file.Package.ImportPath, "-run", testName).Run()
-			if ctx.Err() != nil {
-				fmt.Println("SKIP, Test Timed out")
-				continue
-			}
-			if err != nil {
+	ex.Files.Reset()
+
+	for i, mutation := range selected {
+		mutation.Id = i + 1
+	}
 
-				mutation.Alive = false
-				fmt.Println("Mutant Killed!")
-				break
+	workers := NewWorkers(ex.Config.Workers, ex.Config.Overlay)
+	// All mutations of a schema are compiled at once and picked at runtime
+	schemata := make(chan *Schema)
+	wg := sync.WaitGroup{}
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(w *Worker) {
+			defer wg.Done()
+			for schema := range schemata {
+				w.Execute(ex, schema)
 			}
-		}
-		if mutation.Alive {
-			fmt.Println("MUTANT SURVIVED: " + mutation.Change.Issuer + ", " + mutation.File.Path)
-			color.Red(mutation.Change.OldStr)
-			color.Green(mutation.Change.NewStr)
-		}
+		}(worker)
+	}
+	for _, schema := range BuildSchemata(selected, len(workers)) {
+		schemata <- schema
 	}
+	close(schemata)
+	wg.Wait()
+	RemoveWorkers(workers)
 
-	dead := 0
-	total := len(selected)
-	for _, mutant := range selected {
-		if !mutant.Alive {
-			dead += 1
+}
+
+// Follows the definition used by standard tools: detected / valid * 100.
+// Killed, timed out and crashed mutations are detected, survived and not covered ones are undetected,
+// compile errors are invalid and skipped mutations were not executed, both are left out
+func MutationScore(mutations []*Mutation) (float64, bool) {
+	detected, undetected := 0, 0
+	for _, mutation := range mutations {
+		switch mutation.Status {
+		case MS_KILLED, MS_TIMED_OUT, MS_RUNTIME_ERROR:
+			detected += 1
+		case MS_SURVIVED, MS_NOT_COVERED:
+			undetected += 1
 		}
 	}
 
-	fmt.Println("")
-	color.Yellow("MUTATION SCORE: %v%", dead*100/total)
+	if detected+undetected == 0 {
+		return 0, false
+	}
+	return float64(detected*100) / float64(detected+undetected), true
+}
+
+// Percentage of all mutations that some test reaches
+func MutationCoverage(all []*Mutation, reachable []*Mutation) (float64, bool) {
+	if len(all) == 0 {
+		return 0, false
+	}
+	return float64(len(reachable)*100) / float64(len(all)), true
 }
 
 func GenReport(all []*Mutation, selected []*Mutation, reachable []*Mutation) {
@@ -594,6 +1109,25 @@ func GenReport(all []*Mutation, selected []*Mutation, reachable []*Mutation) {
 	}
 
 	report["byOperator"] = countByIssuer
+
+	countByStatus := make(map[string]int)
+	statusByIssuer := make(map[string]map[string]int)
+	for _, mut := range all {
+		countByStatus[mut.Status.String()] += 1
+		if statusByIssuer[mut.Change.Issuer] == nil {
+			statusByIssuer[mut.Change.Issuer] = make(map[string]int)
+		}
+		statusByIssuer[mut.Change.Issuer][mut.Status.String()] += 1
+	}
+	report["byStatus"] = countByStatus
+	report["byOperatorStatus"] = statusByIssuer
+	if score, ok := MutationScore(selected); ok {
+		report["mutationScore"] = score
+	}
+	if coverage, ok := MutationCoverage(all, reachable); ok {
+		report["mutationCoverage"] = coverage
+	}
+
 	res, _ := json.Marshal(report)
 	fmt.Println(string(res))
 }
@@ -603,6 +1137,14 @@ type Config struct {
 	Package      string
 	CoverageFile string
 	Nocov        bool
+	GoCover      bool
+	TestBinary   bool
+	Workers      int
+	Overlay      bool
+	Race         bool
+	// Each test run times out after TimeoutFactor * baseline duration + TimeoutConstant
+	TimeoutFactor   float64
+	TimeoutConstant time.Duration
 }
 
 var ROOT string
@@ -615,6 +1157,13 @@ func main() {
 	flag.StringVar(&config.Directory, "directory", "/home/matheus/Projects/golang-reference/", "project directory")
 	flag.StringVar(&config.Package, "package", "./...", "package to run mutation analysis")
 	flag.StringVar(&config.CoverageFile, "coverage", "", "file with previously collected coverage data")
+	flag.BoolVar(&config.GoCover, "gocover", false, "compute reachability with go test -cover instead of instrumenting the sources")
+	flag.BoolVar(&config.TestBinary, "testbin", false, "compile each test binary once per schema and run it directly")
+	flag.IntVar(&config.Workers, "workers", 1, "number of project copies executing mutations in parallel")
+	flag.BoolVar(&config.Overlay, "overlay", false, "keep mutated files out of the project and compile them with go build -overlay")
+	flag.BoolVar(&config.Race, "race", false, "run the tests with the race detector, so concurrency mutants can be killed by data races")
+	flag.Float64Var(&config.TimeoutFactor, "timeout-factor", 1.5, "factor applied to the baseline duration of the tests when running mutations")
+	flag.DurationVar(&config.TimeoutConstant, "timeout-constant", 5*time.Second, "time added to the timeout of the tests when running mutations")
 	flag.Parse()
 
 	wd, _ := exec.Command("pwd").Output()
@@ -0,0 +1,313 @@
+// The goal of this step is to run the tests that reach each mutation
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	BIN_DIR       = ".golang-mut/bin"
+	OVERLAY_DIR   = ".golang-mut/overlay"
+	TIMEOUT_GRACE = 10 * time.Second // Extra time before killing tests that ignore their own timeout
+)
+
+// Test binaries built for the schema being executed, indexed by import path
+type TestBinaries map[string]string
+
+// Everything the workers need to execute mutations
+type Execution struct {
+	Config        Config
+	Files         *FileTable
+	TestsPerBlock map[NodeIdentifier][]TestIdentifier
+	Baseline      Baseline
+}
+
+// A copy of the project where schemata are executed, workers run in parallel.
+// In overlay mode all workers share the same project and keep the mutated files on the side
+type Worker struct {
+	Id      int
+	Root    string
+	Overlay string // Directory with the files given to go build -overlay
+}
+
+// Keeps the output of a mutation together when workers run in parallel
+var OUTPUT_LOCK sync.Mutex
+
+// The first worker uses the temporary project, the others get copies of it unless overlays are used
+func NewWorkers(n int, overlay bool) []*Worker {
+	if n < 1 {
+		n = 1
+	}
+
+	workers := []*Worker{}
+	for i := 0; i < n; i++ {
+		w := &Worker{Id: i, Root: TMP_ROOT}
+		if overlay {
+			w.Overlay = filepath.Join(TMP_ROOT, OVERLAY_DIR, strconv.Itoa(i))
+		} else if i > 0 {
+			w.Root = copyProject(TMP_ROOT)
+		}
+		workers = append(workers, w)
+	}
+	return workers
+}
+
+func RemoveWorkers(workers []*Worker) {
+	for _, w := range workers {
+		if w.Root != TMP_ROOT {
+			removeProjectCopy(w.Root)
+		}
+	}
+}
+
+// Location of a path from the temporary project inside the worker copy
+func (w *Worker) Path(path string) string {
+	rel, err := filepath.Rel(TMP_ROOT, path)
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(w.Root, rel)
+}
+
+// Mapping of the mutated files, it lives next to the overlay directory
+func (w *Worker) OverlayFile() string {
+	return w.Overlay + ".json"
+}
+
+// Flags given to go commands that compile the schema
+func (w *Worker) buildFlags(cfg Config) []string {
+	flags := []string{}
+	if cfg.Race {
+		flags = append(flags, "-race")
+	}
+	if w.Overlay != "" {
+		flags = append(flags, "-overlay", w.OverlayFile())
+	}
+	return flags
+}
+
+// Writes the schema, compiles it and runs the tests of each of its mutations
+func (w *Worker) Execute(ex *Execution, schema *Schema) {
+	schema.Write(w)
+	defer schema.Reset(w)
+
+	var binaries TestBinaries
+	if ex.Config.TestBinary {
+		binaries = w.BuildTestBinaries(ex, schema)
+	}
+	if (ex.Config.TestBinary && binaries == nil) || (!ex.Config.TestBinary && !w.Compiles(ex, schema)) {
+		// Isolate the mutations that broke the compilation
+		if len(schema.Mutations) > 1 {
+			Verbosef("SPLIT schema with %d mutations\n", len(schema.Mutations))
+			schema.Reset(w)
+			for _, single := range schema.Split() {
+				w.Execute(ex, single)
+			}
+			return
+		}
+		schema.Mutations[0].Status = MS_COMPILE_ERROR
+		fmt.Println("Mutant does not compile")
+		return
+	}
+
+	for _, mutation := range schema.Mutations {
+		if ex.Config.TestBinary {
+			w.ExecuteMutationBinaries(ex, mutation, binaries)
+		} else {
+			w.ExecuteMutation(ex, mutation)
+		}
+	}
+}
+
+// Packages of the tests that reach the given mutations
+func (ex *Execution) testPackages(mutations []*Mutation) map[string]*PackageInfo {
+	packages := make(map[string]*PackageInfo)
+	for _, mutation := range mutations {
+		for _, test := range ex.TestsPerBlock[mutation.Identifier()] {
+			packages[test.Package] = ex.Files.Packages[test.Package]
+		}
+	}
+	return packages
+}
+
+// Compiles every test package needed by the mutations of the schema, without running any test
+func (w *Worker) Compiles(ex *Execution, s *Schema) bool {
+	args := append([]string{"test", "-count=1", "-run", "^$"}, w.buildFlags(ex.Config)...)
+	for path := range ex.testPackages(s.Mutations) {
+		args = append(args, path)
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = w.Root
+	return cmd.Run() == nil
+}
+
+// Builds the test binary of every package needed by the mutations of the schema with go test -c.
+// Binaries are kept in the temporary project until the next schema, returns nil if any package doesn't compile
+func (w *Worker) BuildTestBinaries(ex *Execution, s *Schema) TestBinaries {
+	dir := filepath.Join(w.Root, BIN_DIR, strconv.Itoa(w.Id))
+	err := os.MkdirAll(dir, 0777)
+	if err != nil {
+		panic(err)
+	}
+
+	binaries := TestBinaries{}
+	for path := range ex.testPackages(s.Mutations) {
+		bin := filepath.Join(dir, strings.ReplaceAll(path, "/", "_")+".test")
+		Verbosef("AT (%s) EXEC go test -c -o %s %s\n", w.Root, bin, path)
+		args := append([]string{"test", "-c", "-o", bin}, w.buildFlags(ex.Config)...)
+		cmd := exec.Command("go", append(args, path)...)
+		cmd.Dir = w.Root
+		if cmd.Run() != nil {
+			return nil
+		}
+		binaries[path] = bin
+	}
+	return binaries
+}
+
+func (w *Worker) ExecuteMutation(ex *Execution, mutation *Mutation) {
+	mutation.Status = MS_NOT_COVERED
+	output := bytes.Buffer{}
+	tests := ex.TestsPerBlock[mutation.Identifier()]
+	for _, test := range tests {
+		timeout := ex.Baseline.Timeout(ex.Config, test.Package, []string{test.Name})
+		ctx, cancel := context.WithTimeout(context.Background(), timeout+TIMEOUT_GRACE)
+
+		fmt.Fprintln(&output, "go test "+test.Package+" -run '"+test.RunPattern()+"'")
+		args := append([]string{"test", "-count=1", "-timeout", timeout.String()}, w.buildFlags(ex.Config)...)
+		cmd := exec.CommandContext(ctx, "go", append(args, test.Package, "-run", test.RunPattern())...)
+		cmd.Dir = w.Root
+		cmd.Env = append(os.Environ(), fmt.Sprintf("EnabledMutation=%d", mutation.Id))
+		out, err := cmd.CombinedOutput()
+		mutation.Status = testStatus(err, out, ctx.Err() != nil)
+		cancel()
+
+		if mutation.Status != MS_SURVIVED {
+			fmt.Fprintf(&output, "Mutant %s!\n", mutation.Status)
+			break
+		}
+	}
+	if mutation.Status == MS_SURVIVED {
+		reportSurvivor(&output, mutation)
+	}
+	printOutput(output.Bytes())
+}
+
+// Runs each package binary once with all the tests that reach the mutation, stopping at the first failure
+func (w *Worker) ExecuteMutationBinaries(ex *Execution, mutation *Mutation, binaries TestBinaries) {
+	mutation.Status = MS_NOT_COVERED
+	output := bytes.Buffer{}
+	for _, run := range testRuns(ex.TestsPerBlock[mutation.Identifier()]) {
+		pkg := ex.Files.Packages[run.Package]
+		bin := binaries[run.Package]
+		pattern := run.Pattern
+		timeout := ex.Baseline.Timeout(ex.Config, run.Package, run.Names)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout+TIMEOUT_GRACE)
+
+		fmt.Fprintln(&output, filepath.Base(bin)+" -test.run '"+pattern+"'")
+		cmd := exec.CommandContext(ctx, bin, "-test.run", pattern, "-test.timeout", timeout.String(), "-test.failfast")
+		cmd.Dir = w.Path(pkg.Dir)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("EnabledMutation=%d", mutation.Id))
+		out, err := cmd.CombinedOutput()
+		mutation.Status = testStatus(err, out, ctx.Err() != nil)
+		cancel()
+
+		if mutation.Status != MS_SURVIVED {
+			fmt.Fprintf(&output, "Mutant %s!\n", mutation.Status)
+			break
+		}
+	}
+	if mutation.Status == MS_SURVIVED {
+		reportSurvivor(&output, mutation)
+	}
+	printOutput(output.Bytes())
+}
+
+// One execution of a test binary
+type testRun struct {
+	Package string
+	Pattern string
+	Names   []string
+}
+
+// Groups the tests of each package in as few runs as possible.
+// Top level tests share a single pattern, but subtests need one run each
+// because -test.run patterns match each level of the name separately
+func testRuns(tests []TestIdentifier) []testRun {
+	runs := []testRun{}
+	subtests := []testRun{}
+	topLevel := make(map[string][]string)
+	packages := []string{}
+	for _, test := range tests {
+		if strings.Contains(test.Name, "/") {
+			subtests = append(subtests, testRun{test.Package, test.RunPattern(), []string{test.Name}})
+			continue
+		}
+		if topLevel[test.Package] == nil {
+			packages = append(packages, test.Package)
+		}
+		topLevel[test.Package] = append(topLevel[test.Package], test.Name)
+	}
+
+	for _, pkg := range packages {
+		names := topLevel[pkg]
+		quoted := []string{}
+		for _, name := range names {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+		runs = append(runs, testRun{pkg, "^(" + strings.Join(quoted, "|") + ")$", names})
+	}
+	return append(runs, subtests...)
+}
+
+// Status of a mutation given the result of running its tests with go test or a test binary
+func testStatus(err error, out []byte, timedOut bool) MutationStatus {
+	if timedOut {
+		return MS_TIMED_OUT
+	}
+	if err == nil {
+		return MS_SURVIVED
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		panic(err)
+	}
+
+	switch {
+	case bytes.Contains(out, []byte("panic: test timed out")):
+		return MS_TIMED_OUT
+	case bytes.Contains(out, []byte("[build failed]")), bytes.Contains(out, []byte("[setup failed]")):
+		return MS_COMPILE_ERROR
+	case bytes.Contains(out, []byte("fatal error: ")):
+		return MS_RUNTIME_ERROR
+	}
+	// Exit status 1 with failing tests, a test that panics has failed as well
+	return MS_KILLED
+}
+
+func reportSurvivor(w io.Writer, mutation *Mutation) {
+	fmt.Fprintln(w, "MUTANT SURVIVED: "+mutation.Change.Issuer+", "+mutation.File.Path)
+	color.New(color.FgRed).Fprintln(w, mutation.Change.OldStr)
+	color.New(color.FgGreen).Fprintln(w, mutation.Change.NewStr)
+}
+
+// The output of a mutation is buffered while its tests run and printed at once
+func printOutput(output []byte) {
+	OUTPUT_LOCK.Lock()
+	defer OUTPUT_LOCK.Unlock()
+
+	os.Stdout.Write(output)
+}
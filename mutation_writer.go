@@ -0,0 +1,272 @@
+// The goal of this step is to write the selected mutations in the form of mutation schemata
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	SCHEMATA_FILE       = "golang_mut_schemata.go"
+	SCHEMATA_DEFINITION = `package %s
+
+import (
+	"os"
+	"strconv"
+)
+
+var __MUTANT, _ = strconv.Atoi(os.Getenv("EnabledMutation"))
+
+func __mutant(id int) bool {
+	return __MUTANT == id
+}
+`
+)
+
+// A set of mutations that are written to the project at the same time, so they share one compilation.
+// When guarded, every mutation is wrapped in its own switch and only takes effect when its id
+// is set in the EnabledMutation environment variable, otherwise the schema holds a single mutation
+type Schema struct {
+	Mutations []*Mutation
+	Guarded   bool
+}
+
+// All mutations on the same statement, they become a single if-else chain
+type stmtGroup struct {
+	Stmt      ast.Stmt
+	Mutations []*Mutation
+}
+
+// Groups the selected mutations in as few schemata as possible, spreading the guarded ones over n schemata.
+// Mutations on statements that can't be wrapped in a switch get a schema of their own
+func BuildSchemata(selected []*Mutation, n int) []*Schema {
+	guarded := []*Schema{}
+	for i := 0; i < n; i++ {
+		guarded = append(guarded, &Schema{Guarded: true})
+	}
+
+	schemata := []*Schema{}
+	wrappable := make(map[*FileInfo]map[ast.Stmt]bool)
+	for _, mutation := range selected {
+		file := mutation.File
+		if wrappable[file] == nil {
+			wrappable[file] = file.wrappableStmts()
+		}
+
+		if wrappable[file][mutation.Change.Stmt] {
+			schema := guarded[mutation.Id%n]
+			schema.Mutations = append(schema.Mutations, mutation)
+		} else {
+			schemata = append(schemata, &Schema{[]*Mutation{mutation}, false})
+		}
+	}
+
+	for i := len(guarded) - 1; i >= 0; i-- {
+		if len(guarded[i].Mutations) > 0 {
+			schemata = append([]*Schema{guarded[i]}, schemata...)
+		}
+	}
+	return schemata
+}
+
+// Splits the schema to isolate mutations that don't compile. Mutations of different packages are
+// separated first, then the schema is cut in halves, so a few broken mutations take a few compilations
+func (s *Schema) Split() []*Schema {
+	schemata := []*Schema{}
+	index := make(map[*PackageInfo]*Schema)
+	for _, mutation := range s.Mutations {
+		pkg := mutation.File.Package
+		if index[pkg] == nil {
+			index[pkg] = &Schema{Guarded: s.Guarded}
+			schemata = append(schemata, index[pkg])
+		}
+		index[pkg].Mutations = append(index[pkg].Mutations, mutation)
+	}
+	if len(schemata) > 1 {
+		return schemata
+	}
+
+	half := len(s.Mutations) / 2
+	return []*Schema{{s.Mutations[:half], s.Guarded}, {s.Mutations[half:], s.Guarded}}
+}
+
+func (s *Schema) byFile() map[*FileInfo][]*Mutation {
+	files := make(map[*FileInfo][]*Mutation)
+	for _, mutation := range s.Mutations {
+		files[mutation.File] = append(files[mutation.File], mutation)
+	}
+	return files
+}
+
+// Returns the content of every file the schema changes or creates, indexed by path
+func (s *Schema) Sources() map[string][]byte {
+	sources := make(map[string][]byte)
+	for file, mutations := range s.byFile() {
+		if !s.Guarded {
+			sources[file.Path] = mutations[0].Source()
+			continue
+		}
+
+		writer := bytes.Buffer{}
+		writeSchemata(&writer, file.Source, groupByStmt(mutations), 0, token.Pos(len(file.Source)))
+		sources[file.Path] = writer.Bytes()
+
+		helper := filepath.Join(filepath.Dir(file.Path), SCHEMATA_FILE)
+		sources[helper] = []byte(fmt.Sprintf(SCHEMATA_DEFINITION, file.AST.Name.Name))
+	}
+	return sources
+}
+
+// Writes the schema to the worker copy of the project, or to its overlay directory
+func (s *Schema) Write(w *Worker) {
+	if w.Overlay != "" {
+		s.writeOverlay(w)
+		return
+	}
+
+	for path, source := range s.Sources() {
+		Verbosef("WRITE %s\n", w.Path(path))
+		os.WriteFile(w.Path(path), source, 0777)
+	}
+}
+
+// Writes the sources to the overlay directory, along with the mapping used by go build -overlay.
+// The project itself is never changed, so workers can share it
+func (s *Schema) writeOverlay(w *Worker) {
+	overlay := struct{ Replace map[string]string }{make(map[string]string)}
+	for path, source := range s.Sources() {
+		rel, err := filepath.Rel(TMP_ROOT, path)
+		if err != nil {
+			panic(err)
+		}
+
+		side := filepath.Join(w.Overlay, rel)
+		err = os.MkdirAll(filepath.Dir(side), 0777)
+		if err != nil {
+			panic(err)
+		}
+		Verbosef("WRITE %s\n", side)
+		os.WriteFile(side, source, 0644)
+		overlay.Replace[path] = side
+	}
+
+	mapping, _ := json.Marshal(overlay)
+	os.WriteFile(w.OverlayFile(), mapping, 0644)
+}
+
+// Restores every file changed by the schema and removes the __mutant definitions
+func (s *Schema) Reset(w *Worker) {
+	if w.Overlay != "" {
+		os.RemoveAll(w.Overlay)
+		os.Remove(w.OverlayFile())
+		return
+	}
+
+	for file := range s.byFile() {
+		os.WriteFile(w.Path(file.Path), file.Source, 0777)
+		if s.Guarded {
+			os.Remove(w.Path(filepath.Join(filepath.Dir(file.Path), SCHEMATA_FILE)))
+		}
+	}
+}
+
+// Groups mutations by the statement they replace, sorted so enclosing statements come first
+func groupByStmt(mutations []*Mutation) []*stmtGroup {
+	groups := []*stmtGroup{}
+	index := make(map[[2]token.Pos]*stmtGroup)
+	for _, mutation := range mutations {
+		stmt := mutation.Change.Stmt
+		key := [2]token.Pos{stmt.Pos(), stmt.End()}
+		if index[key] == nil {
+			index[key] = &stmtGroup{Stmt: stmt}
+			groups = append(groups, index[key])
+		}
+		index[key].Mutations = append(index[key].Mutations, mutation)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Stmt.Pos() != groups[j].Stmt.Pos() {
+			return groups[i].Stmt.Pos() < groups[j].Stmt.Pos()
+		}
+		return groups[i].Stmt.End() > groups[j].Stmt.End()
+	})
+	return groups
+}
+
+// Writes source[from:to] replacing each group statement by:
+//
+//	if __mutant(1) { <mutated> } else if __mutant(2) { <mutated> } else { <original> }
+//
+// Groups nested in another group are written inside the original branch of the enclosing one.
+// Everything stays in the same line, so positions before the statement are preserved
+func writeSchemata(writer *bytes.Buffer, source []byte, groups []*stmtGroup, from token.Pos, to token.Pos) {
+	current := from
+	for i := 0; i < len(groups); {
+		group := groups[i]
+		stmt := group.Stmt
+
+		// Groups after this one that are inside of it
+		j := i + 1
+		for j < len(groups) && groups[j].Stmt.End() <= stmt.End() {
+			j++
+		}
+
+		writer.Write(source[current:stmt.Pos()])
+		for _, mutation := range group.Mutations {
+			fmt.Fprintf(writer, "if __mutant(%d) { %s } else ", mutation.Id, mutation.Change.NewStr)
+		}
+		writer.WriteString("{ ")
+		writeSchemata(writer, source, groups[i+1:j], stmt.Pos(), stmt.End())
+		writer.WriteString(" }")
+
+		current = stmt.End()
+		i = j
+	}
+	writer.Write(source[current:to])
+}
+
+// Statements that can be replaced by an if-else switch without changing the program.
+// They must belong to a statement list and can't declare anything used outside of them
+func (file *FileInfo) wrappableStmts() map[ast.Stmt]bool {
+	stmts := make(map[ast.Stmt]bool)
+	ast.Inspect(file.AST, func(node ast.Node) bool {
+		for _, stmt := range stmtList(node) {
+			if isWrappable(stmt) {
+				stmts[stmt] = true
+			}
+		}
+		return true
+	})
+	return stmts
+}
+
+func isWrappable(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.CaseClause, *ast.CommClause, *ast.DeclStmt, *ast.LabeledStmt:
+		return false
+	case *ast.AssignStmt:
+		if s.Tok == token.DEFINE {
+			return false
+		}
+	case *ast.BranchStmt:
+		if s.Tok == token.FALLTHROUGH {
+			return false
+		}
+	}
+
+	// The statement is duplicated by the switch, so labels inside of it would be redeclared
+	hasLabel := false
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		if _, ok := node.(*ast.LabeledStmt); ok {
+			hasLabel = true
+		}
+		return !hasLabel
+	})
+	return !hasLabel
+}
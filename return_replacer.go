@@ -0,0 +1,163 @@
+// Return value (RET) mutators, they change what a function returns
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+type RETZeroValue struct{}
+
+func (RETZeroValue) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, typ := returnedValue(orig, path, info)
+	if expr == nil || isError(typ) || isCollection(typ) || isZero(expr, info) {
+		return nil
+	}
+	return exprReplacement("RETZeroValue", source, path, expr, zeroValue(typ, path[0].(*ast.File), info))
+}
+
+type RETNegateBool struct{}
+
+func (RETNegateBool) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, typ := returnedValue(orig, path, info)
+	if expr == nil {
+		return nil
+	}
+	if basic, ok := typ.Underlying().(*types.Basic); !ok || basic.Info()&types.IsBoolean == 0 {
+		return nil
+	}
+
+	stmt := RootStmt(path)
+	new := &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: expr}}
+	oldStr, newStr := MutationString(source, stmt, orig, new)
+	return &Replacement{"RETNegateBool", orig, stmt, newStr, oldStr}
+}
+
+type RETNilError struct{}
+
+func (RETNilError) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, typ := returnedValue(orig, path, info)
+	if expr == nil || !isError(typ) || isZero(expr, info) {
+		return nil
+	}
+	return exprReplacement("RETNilError", source, path, expr, "nil")
+}
+
+type RETNewError struct{}
+
+func (RETNewError) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, typ := returnedValue(orig, path, info)
+	if expr == nil || !isError(typ) || !isZero(expr, info) {
+		return nil
+	}
+	return exprReplacement("RETNewError", source, path, expr, newError(path[0].(*ast.File)))
+}
+
+type RETNilCollection struct{}
+
+func (RETNilCollection) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	expr, typ := returnedValue(orig, path, info)
+	if expr == nil || !isCollection(typ) || isZero(expr, info) {
+		return nil
+	}
+	return exprReplacement("RETNilCollection", source, path, expr, "nil")
+}
+
+// Replaces the expression with the given source, skipped if it is empty
+func exprReplacement(issuer string, source string, path []ast.Node, expr ast.Expr, value string) *Replacement {
+	stmt := RootStmt(path)
+	if value == "" || stmt == nil {
+		return nil
+	}
+
+	new, err := parser.ParseExpr(value)
+	if err != nil {
+		panic(err)
+	}
+	oldStr, newStr := MutationString(source, stmt, expr, new)
+	return &Replacement{issuer, expr, stmt, newStr, oldStr}
+}
+
+// Expression returned by a return statement, along with the result type declared by the enclosing function
+func returnedValue(orig ast.Node, path []ast.Node, info *types.Info) (ast.Expr, types.Type) {
+	expr, ok := orig.(ast.Expr)
+	if !ok || info == nil || len(path) < 2 {
+		return nil, nil
+	}
+	ret, ok := path[len(path)-2].(*ast.ReturnStmt)
+	if !ok {
+		return nil, nil
+	}
+
+	var fun *ast.FuncType
+	for i := len(path) - 2; i >= 0 && fun == nil; i-- {
+		switch f := path[i].(type) {
+		case *ast.FuncDecl:
+			fun = f.Type
+		case *ast.FuncLit:
+			fun = f.Type
+		}
+	}
+	if fun == nil || fun.Results == nil {
+		return nil, nil
+	}
+
+	results := []types.Type{}
+	for _, field := range fun.Results.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			results = append(results, info.TypeOf(field.Type))
+		}
+	}
+	// return f() with a function that has many results
+	if len(results) != len(ret.Results) {
+		return nil, nil
+	}
+
+	for i, result := range ret.Results {
+		if result == expr && results[i] != nil {
+			return expr, results[i]
+		}
+	}
+	return nil, nil
+}
+
+// Error value built with the packages the file already imports, empty if it imports none of them
+func newError(file *ast.File) string {
+	if name := importName(file, "errors", "errors"); name != "" {
+		return name + `.New("mutated")`
+	} else if name := importName(file, "fmt", "fmt"); name != "" {
+		return name + `.Errorf("mutated")`
+	}
+	return ""
+}
+
+func isError(typ types.Type) bool {
+	return types.Identical(typ, types.Universe.Lookup("error").Type())
+}
+
+func isCollection(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		return true
+	}
+	return false
+}
+
+// Whether the expression is nil, an empty composite literal or a constant zero value,
+// so replacing it by zero would change nothing
+func isZero(expr ast.Expr, info *types.Info) bool {
+	if lit, ok := expr.(*ast.CompositeLit); ok && len(lit.Elts) == 0 {
+		return true
+	}
+
+	tv := info.Types[expr]
+	if tv.IsNil() {
+		return true
+	}
+	if tv.Value == nil {
+		return false
+	}
+	return tv.Value.ExactString() == "0" || tv.Value.ExactString() == `""` || tv.Value.ExactString() == "false"
+}
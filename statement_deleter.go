@@ -0,0 +1,180 @@
+// Statement deletion (SDL) mutators, they remove a statement along with its side effects
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+type SDLExpression struct{}
+
+func (SDLExpression) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	// Calls without results are left to CALLRemoveVoid
+	stmt, ok := orig.(*ast.ExprStmt)
+	if !ok || isVoidCall(stmt, info) || isTerminatingPanic(stmt, path) {
+		return nil
+	}
+	return statementDeletion("SDLExpression", source, path, stmt)
+}
+
+type SDLAssignment struct{}
+
+func (SDLAssignment) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	// Deleting a declaration would leave its uses undefined
+	stmt, ok := orig.(*ast.AssignStmt)
+	if !ok || stmt.Tok == token.DEFINE {
+		return nil
+	}
+	// Assignments to _ would be replaced by themselves
+	blank := true
+	for _, lhs := range stmt.Lhs {
+		if ident, ok := lhs.(*ast.Ident); !ok || ident.Name != "_" {
+			blank = false
+		}
+	}
+	if blank {
+		return nil
+	}
+	return statementDeletion("SDLAssignment", source, path, stmt)
+}
+
+type SDLDefer struct{}
+
+func (SDLDefer) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	// Deferred cancels, closes and wait group calls are left to CTXRemoveCancel and the CON mutators
+	stmt, ok := orig.(*ast.DeferStmt)
+	if !ok || isCancel(stmt.Call, info) || isConcurrencyCall(stmt.Call, info) {
+		return nil
+	}
+	return statementDeletion("SDLDefer", source, path, stmt)
+}
+
+type SDLGo struct{}
+
+func (SDLGo) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.GoStmt)
+	if !ok {
+		return nil
+	}
+	return statementDeletion("SDLGo", source, path, stmt)
+}
+
+type SDLSend struct{}
+
+func (SDLSend) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.SendStmt)
+	if !ok {
+		return nil
+	}
+	return statementDeletion("SDLSend", source, path, stmt)
+}
+
+type SDLIncDec struct{}
+
+func (SDLIncDec) replacement(source string, orig ast.Node, path []ast.Node, info *types.Info) *Replacement {
+	stmt, ok := orig.(*ast.IncDecStmt)
+	if !ok {
+		return nil
+	}
+	return statementDeletion("SDLIncDec", source, path, stmt)
+}
+
+// Replaces the statement by an empty one, or by _ = with the local variables it used,
+// so the variables that were only used by it still compile
+func statementDeletion(issuer string, source string, path []ast.Node, stmt ast.Stmt) *Replacement {
+	if RootStmt(path) != stmt || !isDeletable(stmt, path) {
+		return nil
+	}
+
+	newStr := ""
+	if vars := usedVariables(stmt); len(vars) > 0 {
+		blanks := strings.Repeat("_, ", len(vars)-1) + "_"
+		newStr = blanks + " = " + strings.Join(vars, ", ")
+	}
+	return &Replacement{issuer, stmt, stmt, newStr, source[stmt.Pos():stmt.End()]}
+}
+
+// Statements that are part of a switch or select header can't be removed
+func isDeletable(stmt ast.Stmt, path []ast.Node) bool {
+	if len(path) < 2 {
+		return false
+	}
+	switch parent := path[len(path)-2].(type) {
+	case *ast.TypeSwitchStmt:
+		return parent.Assign != stmt
+	case *ast.CommClause:
+		return parent.Comm != stmt
+	}
+	return true
+}
+
+// A panic that ends a statement list may be what makes a function terminate,
+// without it the function would be missing a return
+func isTerminatingPanic(stmt *ast.ExprStmt, path []ast.Node) bool {
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "panic" || ident.Obj != nil {
+		return false
+	}
+
+	list := stmtList(path[len(path)-2])
+	return len(list) > 0 && list[len(list)-1] == stmt
+}
+
+// Whether the statement at path[i] is what makes its function terminate: the last statement of a function
+// with results, or the last statement of a branch of a statement that is. Without it the function would be missing a return
+func isTerminatingStmt(path []ast.Node, i int) bool {
+	stmt := path[i]
+	for j := i - 1; j >= 0; j-- {
+		switch parent := path[j].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			// Every clause of a switch or select must terminate, not only the last one
+			switch stmt.(type) {
+			case *ast.CaseClause, *ast.CommClause:
+			default:
+				list := stmtList(parent)
+				if len(list) == 0 || list[len(list)-1] != stmt {
+					return false
+				}
+			}
+		case *ast.IfStmt:
+			if parent.Else == nil {
+				return false
+			}
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.LabeledStmt:
+		case *ast.FuncDecl:
+			return parent.Type.Results != nil && len(parent.Type.Results.List) > 0
+		case *ast.FuncLit:
+			return parent.Type.Results != nil && len(parent.Type.Results.List) > 0
+		default:
+			return false
+		}
+		stmt = path[j]
+	}
+	return false
+}
+
+// Local variables used by the statement, except the ones declared inside of it
+func usedVariables(stmt ast.Stmt) []string {
+	vars := []string{}
+	seen := make(map[string]bool)
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok || ident.Obj == nil || ident.Obj.Kind != ast.Var || ident.Name == "_" || seen[ident.Name] {
+			return true
+		}
+
+		decl, ok := ident.Obj.Decl.(ast.Node)
+		if !ok || (decl.Pos() >= stmt.Pos() && decl.End() <= stmt.End()) {
+			return true
+		}
+		seen[ident.Name] = true
+		vars = append(vars, ident.Name)
+		return true
+	})
+	return vars
+}
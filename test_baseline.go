@@ -0,0 +1,101 @@
+// The goal of this step is to make sure the unmutated test suite passes, and to measure how long each test takes
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Event emitted by go test -json
+type TestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64 // Seconds
+}
+
+// Duration of each test on the unmutated code, indexed by import path and test name.
+// The duration of the whole package is stored with an empty test name
+type Baseline map[string]map[string]time.Duration
+
+// Runs the test suite of the packages once, exits if any of it fails
+func RunBaseline(cfg Config, packages []PackageInfo) Baseline {
+	args := []string{"test", "-json", "-count=1"}
+	// Races found by the detector must come from the mutations
+	if cfg.Race {
+		args = append(args, "-race")
+	}
+	tested := 0
+	for _, pkg := range packages {
+		if len(pkg.TestGoFiles) > 0 || len(pkg.XTestGoFiles) > 0 {
+			args = append(args, pkg.ImportPath)
+			tested++
+		}
+	}
+	// Without packages go test would run the package in the current directory
+	if tested == 0 {
+		return Baseline{}
+	}
+
+	Verbosef("AT (%s) EXEC go %v\n", TMP_ROOT, args)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = TMP_ROOT
+	out, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		panic(err)
+	}
+
+	baseline := Baseline{}
+	failed := []string{}
+	// Build errors and other messages that are not part of the json output
+	other := bytes.Buffer{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		event := TestEvent{}
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			other.Write(scanner.Bytes())
+			other.WriteByte('\n')
+			continue
+		}
+
+		switch event.Action {
+		case "pass":
+			if baseline[event.Package] == nil {
+				baseline[event.Package] = make(map[string]time.Duration)
+			}
+			baseline[event.Package][event.Test] = time.Duration(event.Elapsed * float64(time.Second))
+		case "fail":
+			failed = append(failed, strings.TrimSpace(event.Package+" "+event.Test))
+		}
+	}
+
+	if err != nil || len(failed) > 0 {
+		fmt.Println("The test suite fails without any mutation, it must pass before running mutation analysis:")
+		for _, test := range failed {
+			fmt.Println("\tFAIL " + test)
+		}
+		fmt.Print(other.String())
+		os.Exit(1)
+	}
+	return baseline
+}
+
+// Timeout for running the tests of a package against a mutation: factor * baseline + constant.
+// Tests missing from the baseline are assumed to take as long as their whole package
+func (b Baseline) Timeout(cfg Config, pkg string, tests []string) time.Duration {
+	total := time.Duration(0)
+	for _, test := range tests {
+		duration, ok := b[pkg][test]
+		if !ok {
+			duration = b[pkg][""]
+		}
+		total += duration
+	}
+	return time.Duration(float64(total)*cfg.TimeoutFactor) + cfg.TimeoutConstant
+}
@@ -0,0 +1,302 @@
+// The goal of this step is to type check the packages, so mutators only generate mutants that compile
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Type checks the given files of the package and sets their type information.
+// Errors are not fatal: expressions that could not be checked simply have no type
+func (ft *FileTable) CheckPackage(pkg *PackageInfo, files []*FileInfo) {
+	exports := exportData(pkg)
+	lookup := func(path string) (io.ReadCloser, error) {
+		if exports[path] == "" {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(exports[path])
+	}
+
+	// Our ASTs are parsed without a shared FileSet, which go/types needs.
+	// A copy of each file is checked instead and its results mapped back to our nodes
+	fset := token.NewFileSet()
+	copies := []*ast.File{}
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file.Path, file.Source, 0)
+		if err != nil {
+			panic(err)
+		}
+		copies = append(copies, parsed)
+	}
+
+	checked := newTypesInfo()
+	conf := types.Config{
+		Importer:    importer.ForCompiler(fset, "gc", lookup),
+		FakeImportC: true,
+		Error: func(err error) {
+			Verbosef("TYPE ERROR %s\n", err)
+		},
+	}
+	conf.Check(pkg.ImportPath, fset, copies, checked)
+
+	for i, file := range files {
+		file.Info = newTypesInfo()
+		translateInfo(checked, file.Info, copies[i], file.AST)
+	}
+}
+
+func newTypesInfo() *types.Info {
+	return &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+}
+
+// Location of the export data of the package and its dependencies, indexed by import path
+func exportData(pkg *PackageInfo) map[string]string {
+	Verbosef("AT (%s) EXEC go list -e -export -deps -json %s\n", pkg.ModuleRoot(), pkg.ImportPath)
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-json", pkg.ImportPath)
+	cmd.Dir = pkg.ModuleRoot()
+	out, err := cmd.Output()
+	if err != nil {
+		panic(err)
+	}
+
+	exports := make(map[string]string)
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		dep := struct{ ImportPath, Export string }{}
+		err := decoder.Decode(&dep)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic(err)
+		}
+		exports[dep.ImportPath] = dep.Export
+	}
+	return exports
+}
+
+// Both files come from the same source, so walking them visits the same nodes in the same order
+func translateInfo(from *types.Info, to *types.Info, fromFile *ast.File, toFile *ast.File) {
+	fromNodes := []ast.Node{}
+	ast.Inspect(fromFile, func(node ast.Node) bool {
+		if node != nil {
+			fromNodes = append(fromNodes, node)
+		}
+		return true
+	})
+
+	i := 0
+	ast.Inspect(toFile, func(node ast.Node) bool {
+		if node == nil {
+			return true
+		}
+		if expr, ok := node.(ast.Expr); ok {
+			if tv, ok := from.Types[fromNodes[i].(ast.Expr)]; ok {
+				to.Types[expr] = tv
+			}
+		}
+		if ident, ok := node.(*ast.Ident); ok {
+			if obj := from.Defs[fromNodes[i].(*ast.Ident)]; obj != nil {
+				to.Defs[ident] = obj
+			}
+			if obj := from.Uses[fromNodes[i].(*ast.Ident)]; obj != nil {
+				to.Uses[ident] = obj
+			}
+		}
+		i++
+		return true
+	})
+}
+
+// Whether the operator can replace the one of the expression, given the type of its operands.
+// Without type information every operator is allowed
+func validOperator(info *types.Info, node *ast.BinaryExpr, op token.Token) bool {
+	if info == nil || info.TypeOf(node.X) == nil {
+		return true
+	}
+
+	var flags types.BasicInfo
+	if basic, ok := info.TypeOf(node.X).Underlying().(*types.Basic); ok {
+		flags = basic.Info()
+	}
+	switch op {
+	case token.ADD:
+		if flags&(types.IsNumeric|types.IsString) == 0 {
+			return false
+		}
+	case token.SUB, token.MUL, token.QUO:
+		if flags&types.IsNumeric == 0 {
+			return false
+		}
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT, token.SHL, token.SHR:
+		if flags&types.IsInteger == 0 {
+			return false
+		}
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return flags&types.IsOrdered != 0
+	case token.LAND, token.LOR:
+		return flags&types.IsBoolean != 0
+	}
+
+	// Constant expressions must still be valid constants
+	y := info.Types[node.Y].Value
+	if (op == token.QUO || op == token.REM) && y != nil && constant.Sign(y) == 0 {
+		return false
+	}
+	// Shifts of unsigned constants never give negative values
+	if x := info.Types[node.X].Value; x != nil && y != nil && flags&types.IsUnsigned != 0 && op != token.SHL && op != token.SHR {
+		if op == token.QUO && flags&types.IsInteger != 0 {
+			op = token.QUO_ASSIGN
+		}
+		return constant.Sign(constant.BinaryOp(x, op, y)) >= 0
+	}
+	return true
+}
+
+// Source of the zero value of the type as written in the file, empty if the file can't refer to the type
+func zeroValue(typ types.Type, file *ast.File, info *types.Info) string {
+	if _, ok := typ.(*types.TypeParam); ok {
+		return "*new(" + typ.String() + ")"
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return "false"
+		case t.Info()&types.IsString != 0:
+			return `""`
+		case t.Info()&types.IsNumeric != 0:
+			return "0"
+		case t.Kind() == types.UnsafePointer:
+			return "nil"
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil"
+	case *types.Struct, *types.Array:
+		if name := typeString(typ, file, info); name != "" {
+			return name + "{}"
+		}
+	}
+	return ""
+}
+
+// Source of the type as written in the file, with packages named as the file imports them.
+// Empty if the file doesn't import one of the packages
+func typeString(typ types.Type, file *ast.File, info *types.Info) string {
+	current := filePackage(file, info)
+	ok := true
+	name := types.TypeString(typ, func(pkg *types.Package) string {
+		if pkg == current {
+			return ""
+		}
+		imported := importName(file, pkg.Path(), pkg.Name())
+		if imported == "" {
+			ok = false
+		}
+		return imported
+	})
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// Package being checked, found through the objects declared by the file
+func filePackage(file *ast.File, info *types.Info) *types.Package {
+	for _, decl := range file.Decls {
+		names := []*ast.Ident{}
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			names = append(names, d.Name)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					names = append(names, s.Names...)
+				case *ast.TypeSpec:
+					names = append(names, s.Name)
+				}
+			}
+		}
+		for _, name := range names {
+			if obj := info.Defs[name]; obj != nil && obj.Pkg() != nil {
+				return obj.Pkg()
+			}
+		}
+	}
+	return nil
+}
+
+// Name the file uses for the imported package, empty if it isn't imported by name
+func importName(file *ast.File, path string, name string) string {
+	for _, spec := range file.Imports {
+		if imported, _ := strconv.Unquote(spec.Path.Value); imported != path {
+			continue
+		}
+		if spec.Name == nil {
+			return name
+		} else if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name
+		}
+	}
+	return ""
+}
+
+func isNumeric(typ types.Type) bool {
+	if typ == nil {
+		return false
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsNumeric != 0
+}
+
+// Whether the expression is a variable value being read, so it can be replaced by another value of its type.
+// Constants, types, functions and variables being assigned to are left out
+func isRead(expr ast.Expr, path []ast.Node, info *types.Info) bool {
+	if info == nil || len(path) < 2 {
+		return false
+	}
+	tv, ok := info.Types[expr]
+	if !ok || !tv.IsValue() || tv.Value != nil || tv.Type == nil {
+		return false
+	}
+
+	switch parent := path[len(path)-2].(type) {
+	case *ast.AssignStmt:
+		// Values assigned to _ are never read
+		blank := true
+		for _, lhs := range parent.Lhs {
+			if lhs == expr {
+				return false
+			}
+			if ident, ok := lhs.(*ast.Ident); !ok || ident.Name != "_" {
+				blank = false
+			}
+		}
+		return !blank
+	case *ast.RangeStmt:
+		return parent.X == expr
+	case *ast.IncDecStmt, *ast.SelectorExpr:
+		return false
+	case *ast.UnaryExpr:
+		return parent.Op != token.AND
+	case *ast.CallExpr:
+		return parent.Fun != expr
+	}
+	return true
+}